@@ -0,0 +1,35 @@
+#!/bin/bash
+
+echo "--------------------------------------"
+echo create the local dev JWKS with a random HS256 key
+echo "--------------------------------------"
+
+# the key file is ignored by git, a shared secret in the repo is a forgeable token
+jwks_file=$(dirname "$0")/../jwks/jwks-dev.json
+kid=dev-hs256
+
+b64url(){
+    openssl base64 -A | tr '+/' '-_' | tr -d '='
+}
+
+secret=$(openssl rand 32 | b64url)
+
+cat > $jwks_file <<JWKS
+{
+	"keys": [
+		{"kty": "oct", "kid": "$kid", "alg": "HS256", "use": "sig", "k": "$secret"}
+	]
+}
+JWKS
+chmod 600 $jwks_file
+echo "jwks: $jwks_file (JWT_JWKS_FILE)"
+
+# a token of the tenant $1 (default tenant-dev) valid for 1h with the credit scopes
+tenant=${1:-tenant-dev}
+exp=$(($(date +%s)+3600))
+header=$(printf '{"alg":"HS256","typ":"JWT","kid":"%s"}' $kid | b64url)
+payload=$(printf '{"sub":"dev","tenant_id":"%s","scope":"credit:read credit:write","exp":%s}' $tenant $exp | b64url)
+key_hex=$(printf '%s' "$secret" | tr -- '-_' '+/' | awk '{l=length($0)%4; if(l==2)$0=$0"=="; else if(l==3)$0=$0"="; print}' | openssl base64 -d -A | xxd -p | tr -d '\n')
+signature=$(printf '%s.%s' $header $payload | openssl dgst -sha256 -mac HMAC -macopt hexkey:$key_hex -binary | b64url)
+
+echo "token: $header.$payload.$signature"
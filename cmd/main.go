@@ -1,8 +1,10 @@
 package main
 
 import(
+	"os"
 	"time"
 	"context"
+	_ "time/tzdata"
 	
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -13,7 +15,14 @@ import(
 	"github.com/go-credit/internal/infra/server"
 	"github.com/go-credit/internal/adapter/api"
 	"github.com/go-credit/internal/adapter/database"
+	"github.com/go-credit/internal/adapter/client"
+	"github.com/go-credit/internal/infra/circuitbreaker"
+	"github.com/go-credit/internal/infra/migration"
+	"github.com/go-credit/internal/infra/auth"
+	"github.com/go-credit/internal/infra/telemetry"
 	go_core_pg "github.com/eliezerraj/go-core/database/pg"  
+
+	"go.opentelemetry.io/otel"
 )
 
 var(
@@ -31,26 +40,57 @@ func init(){
 	zerolog.SetGlobalLevel(logLevel)
 
 	infoPod, server := configuration.GetInfoPod()
-	configOTEL 		:= configuration.GetOtelEnv()
 	databaseConfig 	:= configuration.GetDatabaseEnv()
-	apiService 	:= configuration.GetEndpointEnv() 
 
 	appServer.InfoPod = &infoPod
 	appServer.Server = &server
-	appServer.ConfigOTEL = &configOTEL
 	appServer.DatabaseConfig = &databaseConfig
+
+	// the migrate subcommand only needs the database, the auth, OTEL and api configs are not loaded
+	if isMigrate() {
+		return
+	}
+
+	configOTEL 		:= configuration.GetOtelEnv()
+	apiService 	:= configuration.GetEndpointEnv() 
+	idempotencyConfig := configuration.GetIdempotencyEnv()
+	outboxConfig 	:= configuration.GetOutboxEnv()
+	circuitBreakerConfig := configuration.GetCircuitBreakerEnv(apiService)
+	adminConfig 	:= configuration.GetAdminEnv()
+	listConfig 		:= configuration.GetListEnv()
+	migrationConfig := configuration.GetMigrationEnv()
+	holdConfig 		:= configuration.GetHoldEnv()
+	batchConfig 	:= configuration.GetBatchEnv()
+	authConfig 		:= configuration.GetAuthEnv()
+	buildInfo 		:= configuration.GetBuildInfo()
+	configSource 	:= configuration.GetConfigSource(&authConfig)
+	healthConfig 	:= configuration.GetHealthEnv()
+	propagatorConfig := configuration.GetPropagatorEnv()
+
+	appServer.ConfigOTEL = &configOTEL
 	appServer.ApiService = apiService
+	appServer.IdempotencyConfig = &idempotencyConfig
+	appServer.OutboxConfig = &outboxConfig
+	appServer.CircuitBreakerConfig = circuitBreakerConfig
+	appServer.AdminConfig = &adminConfig
+	appServer.ListConfig = &listConfig
+	appServer.MigrationConfig = &migrationConfig
+	appServer.HoldConfig = &holdConfig
+	appServer.BatchConfig = &batchConfig
+	appServer.AuthConfig = &authConfig
+	appServer.BuildInfo = &buildInfo
+	appServer.ConfigSource = &configSource
+	appServer.HealthConfig = &healthConfig
+	appServer.PropagatorConfig = &propagatorConfig
 }
 
-// About main
-func main (){
-	childLogger.Info().Str("func","main").Interface("appServer",appServer).Send()
-
-	ctx, cancel := context.WithTimeout(	context.Background(), 
-										time.Duration( appServer.Server.ReadTimeout ) * time.Second)
-	defer cancel()
+// About the migrate subcommand (go-credit migrate up|down|status)
+func isMigrate() bool {
+	return len(os.Args) > 1 && os.Args[1] == "migrate"
+}
 
-	// Open Database
+// About open the database, 3 attempts
+func openDatabase(ctx context.Context) {
 	count := 1
 	var err error
 	for {
@@ -68,13 +108,77 @@ func main (){
 		}
 		break
 	}
+}
+
+// About main
+func main (){
+	ctx, cancel := context.WithTimeout(	context.Background(), 
+										time.Duration( appServer.Server.ReadTimeout ) * time.Second)
+	defer cancel()
+
+	// migrate subcommand, only the database is opened
+	if isMigrate() {
+		childLogger.Info().Str("func","main").Strs("args", os.Args[1:]).Send()
+
+		openDatabase(ctx)
+		code := runMigrate(context.Background(), os.Args[2:])
+		cancel()
+		os.Exit(code)
+	}
+
+	childLogger.Info().Str("func","main").Interface("appServer",appServer.Redacted()).Send()
+
+	// Open Database
+	openDatabase(ctx)
+
+	// refuse to serve with a schema behind the embedded migrations
+	if appServer.MigrationConfig.SchemaCheck {
+		migrator, err := migration.NewMigrator(&databasePGServer)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("fatal error load migrations aborting")
+			panic(err)
+		}
+		err = migrator.CheckSchema(ctx)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("fatal error schema check aborting")
+			panic(err)
+		}
+	}
+
+	// metrics, the instruments are created with the global meter provider by the wire below
+	meterProvider, err := telemetry.NewMeterProvider(ctx, appServer.InfoPod, appServer.ConfigOTEL)
+	if err != nil {
+		childLogger.Error().Err(err).Msg("fatal error create meter provider aborting")
+		panic(err)
+	}
+	otel.SetMeterProvider(meterProvider.Provider())
+	defer meterProvider.Shutdown(context.Background())
 
 	// wire	
 	database := database.NewWorkerRepository(&databasePGServer)
-	workerService := service.NewWorkerService(database, appServer.ApiService)
-	httpRouters := api.NewHttpRouters(workerService)
+	circuitBreakers := circuitbreaker.NewCircuitBreakers(appServer.CircuitBreakerConfig)
+	restClient := client.NewRestClient(appServer.ApiService, circuitBreakers)
+	workerService := service.NewWorkerService(database, restClient, restClient, appServer.IdempotencyConfig, appServer.OutboxConfig, circuitBreakers, appServer.ListConfig, appServer.HoldConfig, appServer.BatchConfig, appServer.HealthConfig)
+	httpRouters := api.NewHttpRouters(workerService, &appServer)
+
+	// jwt authentication of the credit endpoints
+	var tokenVerifier api.TokenVerifier
+	if appServer.AuthConfig.Enabled {
+		verifier, err := auth.NewVerifier(appServer.AuthConfig)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("fatal error load jwks aborting")
+			panic(err)
+		}
+		tokenVerifier = verifier
+	}
 	httpServer := server.NewHttpAppServer(appServer.Server)
 
+	// start the background workers (outbox relay and hold expiry)
+	workerCtx, workerCancel := context.WithCancel(context.Background())
+	defer workerCancel()
+	go workerService.StartOutboxRelay(workerCtx)
+	go workerService.StartHoldExpiry(workerCtx)
+
 	// start server
-	httpServer.StartHttpAppServer(ctx, &httpRouters, &appServer)
+	httpServer.StartHttpAppServer(ctx, &httpRouters, tokenVerifier, meterProvider.Handler(), &appServer)
 }
\ No newline at end of file
@@ -0,0 +1,64 @@
+package main
+
+import(
+	"context"
+	"fmt"
+
+	"github.com/go-credit/internal/infra/migration"
+)
+
+// About the migrate subcommand (go-credit migrate up|down|status), returns the exit code
+func runMigrate(ctx context.Context, args []string) int {
+	childLogger.Info().Str("func","runMigrate").Strs("args", args).Send()
+
+	if len(args) != 1 {
+		fmt.Println("usage: go-credit migrate up|down|status")
+		return 2
+	}
+
+	migrator, err := migration.NewMigrator(&databasePGServer)
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error load migrations")
+		return 1
+	}
+
+	switch args[0] {
+	case "up":
+		res_list, err := migrator.Up(ctx)
+		for _, status := range res_list {
+			fmt.Printf("applied %04d_%s\n", status.Version, status.Name)
+		}
+		if err != nil {
+			childLogger.Error().Err(err).Msg("error migrate up")
+			return 1
+		}
+		if len(res_list) == 0 {
+			fmt.Printf("schema up to date (version %d)\n", migrator.Latest())
+		}
+	case "down":
+		res, err := migrator.Down(ctx)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("error migrate down")
+			return 1
+		}
+		fmt.Printf("rolled back %04d_%s\n", res.Version, res.Name)
+	case "status":
+		res_list, err := migrator.Status(ctx)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("error migrate status")
+			return 1
+		}
+		for _, status := range res_list {
+			applied_at := "pending"
+			if status.Applied {
+				applied_at = status.AppliedAt.Format("2006-01-02T15:04:05Z07:00")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, applied_at)
+		}
+	default:
+		fmt.Println("usage: go-credit migrate up|down|status")
+		return 2
+	}
+
+	return 0
+}
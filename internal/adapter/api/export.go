@@ -0,0 +1,394 @@
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-credit/internal/core/model"
+)
+
+// the rows written between two flushes of the response
+const exportFlushRows = 100
+
+// a statement format, nothing is written before the first entry (or Close),
+// so an error before it can still be answered with a status code
+type statementWriter interface {
+	Write(credit *model.AccountStatement) error
+	Flush() error
+	Close() error
+}
+
+// the statement being exported, the service completes the filter (date_end) before the first row
+type statementExport struct {
+	AccountID	string
+	CreatedAt	time.Time
+	ListFilter	*model.ListFilter
+}
+
+type exportFormat struct {
+	ContentType		string
+	Extension		string
+	NeedCurrency	bool
+	NewWriter		func(w io.Writer, export *statementExport) statementWriter
+}
+
+// the formats of GET /list/{id}/export?format=
+var exportFormats = map[string]exportFormat{
+	"csv":		{ContentType: "text/csv; charset=utf-8", Extension: "csv", NewWriter: newCsvWriter},
+	"ofx":		{ContentType: "application/x-ofx", Extension: "ofx", NeedCurrency: true, NewWriter: newOfxWriter},
+	"camt053":	{ContentType: "application/xml; charset=utf-8", Extension: "xml", NeedCurrency: true, NewWriter: newCamt053Writer},
+}
+
+// About the attachment name, credit-<account>-<date>.<extension>
+func exportFilename(export *statementExport, format exportFormat) string {
+	account := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, export.AccountID)
+	return fmt.Sprintf("credit-%s-%s.%s", account, export.CreatedAt.Format("20060102"), format.Extension)
+}
+
+// the response of an export, the headers are sent with the first byte
+type exportResponse struct {
+	rw			http.ResponseWriter
+	format		exportFormat
+	filename	string
+	started		bool
+}
+
+func (e *exportResponse) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.rw.Header().Set("Content-Type", e.format.ContentType)
+		e.rw.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": e.filename}))
+		e.rw.Header().Set("Cache-Control", "no-store")
+		e.rw.WriteHeader(http.StatusOK)
+	}
+	return e.rw.Write(p)
+}
+
+// About send the buffered bytes to the client
+func (e *exportResponse) Flush() {
+	if e.started {
+		http.NewResponseController(e.rw).Flush()
+	}
+}
+
+// About escape a text for xml
+func xmlText(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
+
+// About cut a text to the size of a field
+func truncate(value string, size int) string {
+	runes := []rune(value)
+	if len(runes) > size {
+		return string(runes[:size])
+	}
+	return value
+}
+
+// About the transaction id of an entry (the id when there is none)
+func entryReference(credit *model.AccountStatement) string {
+	if credit.TransactionID != nil && *credit.TransactionID != "" {
+		return *credit.TransactionID
+	}
+	return strconv.Itoa(credit.ID)
+}
+
+// ------------------------------------------------------------- csv
+
+type csvWriter struct {
+	writer	*csv.Writer
+	started	bool
+}
+
+func newCsvWriter(w io.Writer, export *statementExport) statementWriter {
+	return &csvWriter{writer: csv.NewWriter(w)}
+}
+
+// About a text field that a spreadsheet would read as a formula
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (c *csvWriter) header() error {
+	c.started = true
+	return c.writer.Write([]string{"id", "transaction_id", "account_id", "type_charge", "charged_at", "currency", "amount", "reversal_of", "reversed_amount", "tenant_id", "obs", "metadata"})
+}
+
+func (c *csvWriter) Write(credit *model.AccountStatement) error {
+	if !c.started {
+		if err := c.header(); err != nil {
+			return err
+		}
+	}
+
+	transaction_id, reversal_of, reversed_amount, metadata := "", "", "", ""
+	if credit.TransactionID != nil {
+		transaction_id = *credit.TransactionID
+	}
+	if credit.ReversalOf != nil {
+		reversal_of = *credit.ReversalOf
+	}
+	if credit.ReversedAmount != nil {
+		reversed_amount = credit.ReversedAmount.String()
+	}
+	if len(credit.Metadata) > 0 {
+		value, err := json.Marshal(credit.Metadata)
+		if err != nil {
+			return err
+		}
+		metadata = string(value)
+	}
+
+	return c.writer.Write([]string{	strconv.Itoa(credit.ID),
+									transaction_id,
+									credit.AccountID,
+									credit.Type,
+									credit.ChargeAt.UTC().Format(time.RFC3339),
+									credit.Currency,
+									credit.Amount.String(),
+									reversal_of,
+									reversed_amount,
+									csvText(credit.TenantID),
+									csvText(credit.Obs),
+									metadata})
+}
+
+func (c *csvWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvWriter) Close() error {
+	if !c.started {
+		if err := c.header(); err != nil {
+			return err
+		}
+	}
+	return c.Flush()
+}
+
+// ------------------------------------------------------------- ofx (2.2, xml)
+
+type ofxWriter struct {
+	writer	*bufio.Writer
+	export	*statementExport
+	started	bool
+	total	model.Money
+}
+
+func newOfxWriter(w io.Writer, export *statementExport) statementWriter {
+	return &ofxWriter{writer: bufio.NewWriter(w), export: export, total: model.NewMoney(0, model.CurrencyScale(export.ListFilter.Currency))}
+}
+
+// About the ofx date, YYYYMMDDHHMMSS.XXX[0:GMT]
+func ofxDate(date time.Time) string {
+	return date.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+// About the document up to the transaction list, the period starts at date_start or the first entry
+func (o *ofxWriter) header(firstEntry time.Time) error {
+	o.started = true
+
+	date_start := o.export.ListFilter.DateStart
+	if date_start.IsZero() {
+		date_start = firstEntry
+	}
+
+	_, err := fmt.Fprintf(o.writer, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>go-credit</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`,	ofxDate(o.export.CreatedAt),
+		xmlText(o.export.ListFilter.Currency),
+		xmlText(truncate(o.export.AccountID, 22)),
+		ofxDate(date_start),
+		ofxDate(o.dateEnd()))
+	return err
+}
+
+// About the end of the period, date_end or the export time
+func (o *ofxWriter) dateEnd() time.Time {
+	if o.export.ListFilter.DateEnd.IsZero() {
+		return o.export.CreatedAt
+	}
+	return o.export.ListFilter.DateEnd
+}
+
+func (o *ofxWriter) Write(credit *model.AccountStatement) error {
+	if !o.started {
+		if err := o.header(credit.ChargeAt); err != nil {
+			return err
+		}
+	}
+
+	total, err := o.total.Add(credit.Amount)
+	if err != nil {
+		return err
+	}
+	o.total = total
+
+	trn_type := "CREDIT"
+	if credit.Amount.IsNegative() {
+		trn_type = "DEBIT"
+	}
+
+	memo := ""
+	if credit.Obs != "" {
+		memo = "<MEMO>" + xmlText(truncate(credit.Obs, 255)) + "</MEMO>"
+	}
+
+	_, err = fmt.Fprintf(o.writer, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME>%s</STMTTRN>\n",
+							trn_type,
+							ofxDate(credit.ChargeAt),
+							credit.Amount.String(),
+							xmlText(truncate(entryReference(credit), 255)),
+							xmlText(credit.Type),
+							memo)
+	return err
+}
+
+func (o *ofxWriter) Flush() error {
+	return o.writer.Flush()
+}
+
+// About close the transaction list, LEDGERBAL is the sum of the exported entries
+func (o *ofxWriter) Close() error {
+	if !o.started {
+		date_start := o.export.ListFilter.DateStart
+		if date_start.IsZero() {
+			date_start = o.dateEnd()
+		}
+		if err := o.header(date_start); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(o.writer, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`, o.total.String(), ofxDate(o.dateEnd()))
+	if err != nil {
+		return err
+	}
+	return o.Flush()
+}
+
+// ------------------------------------------------------------- camt.053 (ISO 20022, 001.02)
+
+type camt053Writer struct {
+	writer	*bufio.Writer
+	export	*statementExport
+	started	bool
+}
+
+func newCamt053Writer(w io.Writer, export *statementExport) statementWriter {
+	return &camt053Writer{writer: bufio.NewWriter(w), export: export}
+}
+
+// About the iso date time
+func camtDate(date time.Time) string {
+	return date.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// About the document up to the entries. The balances are kept by go-account, there is no Bal
+func (c *camt053Writer) header() error {
+	c.started = true
+
+	msg_id := truncate(fmt.Sprintf("%s-%s", c.export.AccountID, c.export.CreatedAt.UTC().Format("20060102150405")), 35)
+
+	from_to := ""
+	if !c.export.ListFilter.DateStart.IsZero() {
+		from_to = fmt.Sprintf("<FrToDt><FrDtTm>%s</FrDtTm><ToDtTm>%s</ToDtTm></FrToDt>", camtDate(c.export.ListFilter.DateStart), camtDate(c.export.ListFilter.DateEnd))
+	}
+
+	_, err := fmt.Fprintf(c.writer, `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+<BkToCstmrStmt>
+<GrpHdr><MsgId>%s</MsgId><CreDtTm>%s</CreDtTm></GrpHdr>
+<Stmt><Id>%s</Id><CreDtTm>%s</CreDtTm>%s
+<Acct><Id><Othr><Id>%s</Id></Othr></Id><Ccy>%s</Ccy></Acct>
+`,	xmlText(msg_id),
+		camtDate(c.export.CreatedAt),
+		xmlText(msg_id),
+		camtDate(c.export.CreatedAt),
+		from_to,
+		xmlText(truncate(c.export.AccountID, 34)),
+		xmlText(c.export.ListFilter.Currency))
+	return err
+}
+
+func (c *camt053Writer) Write(credit *model.AccountStatement) error {
+	if !c.started {
+		if err := c.header(); err != nil {
+			return err
+		}
+	}
+
+	indicator, reversal := "CRDT", ""
+	if credit.Amount.IsNegative() {
+		indicator = "DBIT"
+	}
+	if credit.ReversalOf != nil {
+		reversal = "<RvslInd>true</RvslInd>"
+	}
+
+	info := ""
+	if credit.Obs != "" {
+		info = "<AddtlNtryInf>" + xmlText(truncate(credit.Obs, 500)) + "</AddtlNtryInf>"
+	}
+
+	_, err := fmt.Fprintf(c.writer, "<Ntry><NtryRef>%d</NtryRef><Amt Ccy=\"%s\">%s</Amt><CdtDbtInd>%s</CdtDbtInd>%s<Sts>BOOK</Sts><BookgDt><DtTm>%s</DtTm></BookgDt><ValDt><DtTm>%s</DtTm></ValDt><BkTxCd><Prtry><Cd>%s</Cd></Prtry></BkTxCd><NtryDtls><TxDtls><AddtlTxInf>%s</AddtlTxInf></TxDtls></NtryDtls>%s</Ntry>\n",
+							credit.ID,
+							xmlText(credit.Currency),
+							credit.Amount.Abs().String(),
+							indicator,
+							reversal,
+							camtDate(credit.ChargeAt),
+							camtDate(credit.ChargeAt),
+							xmlText(credit.Type),
+							xmlText(entryReference(credit)),
+							info)
+	return err
+}
+
+func (c *camt053Writer) Flush() error {
+	return c.writer.Flush()
+}
+
+func (c *camt053Writer) Close() error {
+	if !c.started {
+		if err := c.header(); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(c.writer, "</Stmt>\n</BkToCstmrStmt>\n</Document>\n")
+	if err != nil {
+		return err
+	}
+	return c.Flush()
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-credit/internal/core/model"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// the business metrics of the credits
+type creditMetrics struct {
+	added		metric.Int64Counter
+	amount		metric.Float64Histogram
+}
+
+// About create the credit metrics with the global meter provider
+func newCreditMetrics() *creditMetrics {
+	meter := otel.Meter("go-credit")
+
+	added, err := meter.Int64Counter("credit_added",
+							metric.WithDescription("credits added per type, currency, tenant and outcome (success, rejected, error)"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create credit_added metric")
+	}
+	amount, err := meter.Float64Histogram("credit_amount",
+							metric.WithDescription("amount of the credits added per type and currency"),
+							metric.WithExplicitBucketBoundaries(1, 10, 50, 100, 500, 1000, 5000, 10000, 50000, 100000, 1000000))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create credit_amount metric")
+	}
+
+	return &creditMetrics{added: added, amount: amount}
+}
+
+// About the outcome of a status code
+func outcome(statusCode int) string {
+	switch {
+	case statusCode < 400:
+		return "success"
+	case statusCode < 500:
+		return "rejected"
+	default:
+		return "error"
+	}
+}
+
+// About record a credit (or reversal) and its response status, the amount only of a credit added
+func (c *creditMetrics) record(ctx context.Context, credit *model.AccountStatement, statusCode int) {
+	if c == nil {
+		return
+	}
+	if c.added != nil {
+		c.added.Add(ctx, 1, metric.WithAttributes(	attribute.String("type", credit.Type),
+													attribute.String("currency", credit.Currency),
+													attribute.String("tenant", credit.TenantID),
+													attribute.String("outcome", outcome(statusCode))))
+	}
+	if c.amount != nil && statusCode < 400 {
+		c.amount.Record(ctx, credit.Amount.Abs().Float64(), metric.WithAttributes(	attribute.String("type", credit.Type),
+																					attribute.String("currency", credit.Currency)))
+	}
+}
+
+// keeps the status code of the response, Unwrap gives the Flusher back to http.ResponseController
+type statusResponseWriter struct {
+	http.ResponseWriter
+	statusCode	int
+}
+
+func (s *statusResponseWriter) WriteHeader(statusCode int) {
+	if s.statusCode == 0 {
+		s.statusCode = statusCode
+	}
+	s.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (s *statusResponseWriter) Write(b []byte) (int, error) {
+	if s.statusCode == 0 {
+		s.statusCode = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// About middleware that records the duration of the requests per route (path template), method and status code
+func MetricsMiddleware() func(http.Handler) http.Handler {
+	meter := otel.Meter("go-credit")
+	requestDuration, err := meter.Float64Histogram("http_server_request_duration",
+									metric.WithDescription("duration of the http requests per route"),
+									metric.WithUnit("s"),
+									metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create http_server_request_duration metric")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if requestDuration == nil {
+				next.ServeHTTP(rw, req)
+				return
+			}
+
+			// a route without template is not expanded (cardinality)
+			route := "other"
+			if currentRoute := mux.CurrentRoute(req); currentRoute != nil {
+				if template, err := currentRoute.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			start := time.Now()
+			statusWriter := &statusResponseWriter{ResponseWriter: rw}
+
+			// also recorded when the handler aborts the response (panic)
+			defer func() {
+				statusCode := statusWriter.statusCode
+				if statusCode == 0 {
+					statusCode = http.StatusOK
+				}
+				requestDuration.Record(req.Context(), time.Since(start).Seconds(), metric.WithAttributes(	attribute.String("route", route),
+																											attribute.String("method", req.Method),
+																											attribute.Int("status_code", statusCode)))
+			}()
+
+			next.ServeHTTP(statusWriter, req)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"crypto/subtle"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+)
+
+// About middleware that requires the admin credential (Authorization: Bearer <token>)
+func AdminMiddleware(adminConfig *model.AdminConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			childLogger.Debug().Str("func","AdminMiddleware").Send()
+
+			// without a configured token the admin endpoints are disabled
+			if adminConfig == nil || adminConfig.Token == "" {
+				apiError := core_apiError.NewAPIError(erro.ErrHTTPForbiden, http.StatusForbidden)
+				core_json.WriteJSON(rw, apiError.StatusCode, apiError)
+				return
+			}
+
+			token := strings.TrimSpace(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+			if subtle.ConstantTimeCompare([]byte(token), []byte(adminConfig.Token)) != 1 {
+				childLogger.Warn().Str("path", req.URL.Path).Msg("admin request unauthorized")
+
+				apiError := core_apiError.NewAPIError(erro.ErrUnauthorized, http.StatusUnauthorized)
+				core_json.WriteJSON(rw, apiError.StatusCode, apiError)
+				return
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// the scopes of the credit endpoints
+const (
+	ScopeCreditRead		= "credit:read"
+	ScopeCreditWrite	= "credit:write"
+)
+
+type principalKey struct{}
+
+// validates a bearer token, auth.Verifier is the jwks implementation
+type TokenVerifier interface {
+	Verify(token string) (*model.Principal, error)
+}
+
+// About the principal of an authenticated request (nil when the authentication is disabled)
+func PrincipalFromContext(ctx context.Context) *model.Principal {
+	principal, _ := ctx.Value(principalKey{}).(*model.Principal)
+	return principal
+}
+
+// About middleware that requires a valid jwt (Authorization: Bearer <token>) with a scope.
+// Without a verifier the request goes through without principal, the tenant of the handlers refuses it (401) outside the dev mode
+func AuthMiddleware(tokenVerifier TokenVerifier, scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			childLogger.Debug().Str("func","AuthMiddleware").Str("scope", scope).Send()
+
+			if tokenVerifier == nil || req.Method == http.MethodOptions {
+				next.ServeHTTP(rw, req)
+				return
+			}
+
+			authorization := req.Header.Get("Authorization")
+			token, found := strings.CutPrefix(authorization, "Bearer ")
+			if !found || strings.TrimSpace(token) == "" {
+				rw.Header().Set("WWW-Authenticate", `Bearer realm="go-credit"`)
+				apiError := core_apiError.NewAPIError(erro.ErrUnauthorized, http.StatusUnauthorized)
+				core_json.WriteJSON(rw, apiError.StatusCode, apiError)
+				return
+			}
+
+			principal, err := tokenVerifier.Verify(strings.TrimSpace(token))
+			if err != nil {
+				childLogger.Warn().Err(err).Str("path", req.URL.Path).Msg("request unauthorized")
+
+				rw.Header().Set("WWW-Authenticate", `Bearer realm="go-credit", error="invalid_token"`)
+				apiError := core_apiError.NewAPIError(erro.ErrUnauthorized, http.StatusUnauthorized)
+				core_json.WriteJSON(rw, apiError.StatusCode, apiError)
+				return
+			}
+
+			if !slices.Contains(principal.Scopes, scope) {
+				childLogger.Warn().Str("path", req.URL.Path).Str("sub", principal.Subject).Str("scope", scope).Msg("request forbiden, missing scope")
+
+				rw.Header().Set("WWW-Authenticate", `Bearer realm="go-credit", error="insufficient_scope", scope="` + scope + `"`)
+				apiError := core_apiError.NewAPIError(erro.ErrHTTPForbiden, http.StatusForbidden)
+				core_json.WriteJSON(rw, apiError.StatusCode, apiError)
+				return
+			}
+
+			next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), principalKey{}, principal)))
+		})
+	}
+}
+
+// About the tenant of a request, the tenant_id claim of the principal.
+// A request without principal is refused, only in the dev mode (AUTH_DEV_TENANT_HEADER)
+// the tenant comes from the X-Tenant-Id header (or the body). A body with the tenant of another caller is refused
+func (h *HttpRouters) requestTenant(req *http.Request, bodyTenant string) (string, error) {
+	principal := PrincipalFromContext(req.Context())
+	if principal != nil {
+		if principal.TenantID == "" {
+			childLogger.Warn().Str("path", req.URL.Path).Str("sub", principal.Subject).Msg("request forbiden, token without tenant")
+			return "", erro.ErrHTTPForbiden
+		}
+		if bodyTenant != "" && bodyTenant != principal.TenantID {
+			childLogger.Warn().Str("path", req.URL.Path).Str("sub", principal.Subject).Str("tenant_id", bodyTenant).Msg("request forbiden, cross-tenant")
+			return "", erro.ErrHTTPForbiden
+		}
+		return principal.TenantID, nil
+	}
+
+	if h.appServer.AuthConfig == nil || !h.appServer.AuthConfig.DevTenantHeader {
+		childLogger.Warn().Str("path", req.URL.Path).Msg("request unauthorized, without principal")
+		return "", erro.ErrUnauthorized
+	}
+
+	tenantID := req.Header.Get("X-Tenant-Id")
+	if tenantID == "" {
+		tenantID = bodyTenant
+	}
+	if bodyTenant != "" && bodyTenant != tenantID {
+		return "", erro.ErrHTTPForbiden
+	}
+	if tenantID == "" {
+		return "", erro.ErrInvalidParameter
+	}
+	return tenantID, nil
+}
+
+// About the status of a tenant error (401, 403 or 400)
+func tenantStatus(err error) int {
+	switch err {
+	case erro.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case erro.ErrHTTPForbiden:
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
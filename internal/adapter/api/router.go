@@ -1,12 +1,22 @@
 package api
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 	"github.com/rs/zerolog/log"
 	"github.com/go-credit/internal/core/service"
 	"github.com/go-credit/internal/core/model"
 	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/infra/telemetry"
 	go_core_observ "github.com/eliezerraj/go-core/observability"
 	go_core_tools "github.com/eliezerraj/go-core/tools"
 	"github.com/eliezerraj/go-core/coreJson"
@@ -16,27 +26,38 @@ import (
 var childLogger = log.With().Str("component", "go-credit").Str("package", "internal.adapter.api").Logger()
 
 var core_json coreJson.CoreJson
+// only a constructor, each request builds its own APIError (a shared value is a data race)
 var core_apiError coreJson.APIError
 var core_tools go_core_tools.ToolsCore
 var tracerProvider go_core_observ.TracerProvider
 
 type HttpRouters struct {
 	workerService 	*service.WorkerService
+	appServer		*model.AppServer
+	creditMetrics	*creditMetrics
 }
 
-func NewHttpRouters(workerService *service.WorkerService) HttpRouters {
+func NewHttpRouters(workerService *service.WorkerService, appServer *model.AppServer) HttpRouters {
 	childLogger.Info().Str("func","NewHttpRouters").Send()
 
 	return HttpRouters{
 		workerService: workerService,
+		appServer: appServer,
+		creditMetrics: newCreditMetrics(),
 	}
 }
 
-// About return a health
+// About return the readiness, 503 when a critical component is down
 func (h *HttpRouters) Health(rw http.ResponseWriter, req *http.Request) {
 	childLogger.Info().Str("func","Health").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
-	
-	json.NewEncoder(rw).Encode(model.MessageRouter{Message: "true"})
+
+	res := h.workerService.HealthCheck(req.Context())
+
+	status := http.StatusOK
+	if res.Status == "down" {
+		status = http.StatusServiceUnavailable
+	}
+	core_json.WriteJSON(rw, status, res)
 }
 
 // About return a live
@@ -46,6 +67,33 @@ func (h *HttpRouters) Live(rw http.ResponseWriter, req *http.Request) {
 	json.NewEncoder(rw).Encode(model.MessageRouter{Message: "true"})
 }
 
+// About return the app server config with the secrets masked
+func (h *HttpRouters) AppServer(rw http.ResponseWriter, req *http.Request) {
+	childLogger.Info().Str("func","AppServer").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(h.appServer.Redacted())
+}
+
+// About return the build, pod and config source information and the status of the dependencies
+func (h *HttpRouters) Info(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","Info").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	res, err := h.workerService.ListDependency(req.Context())
+	if err != nil {
+		apiError := core_apiError.NewAPIError(err, http.StatusInternalServerError)
+		return &apiError
+	}
+
+	appInfo := model.AppInfo{	BuildInfo: h.appServer.BuildInfo,
+								InfoPod: h.appServer.InfoPod,
+								ConfigSource: h.appServer.ConfigSource,
+								Dependencies: *res,
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, appInfo)
+}
+
 // About show all header received
 func (h *HttpRouters) Header(rw http.ResponseWriter, req *http.Request) {
 	childLogger.Info().Str("func","Header").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()	
@@ -61,36 +109,216 @@ func (h *HttpRouters) AddCredit(rw http.ResponseWriter, req *http.Request) error
 	span := tracerProvider.Span(req.Context(), "adapter.api.AddCredit")
 	defer span.End()
 
+	var apiError coreJson.APIError
+
 	// prepare body
 	credit := model.AccountStatement{}
 	err := json.NewDecoder(req.Body).Decode(&credit)
     if err != nil {
-		core_apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
-		return &core_apiError
+		apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		telemetry.RecordError(span, &apiError)
+		return &apiError
     }
 	defer req.Body.Close()
 
+	// the idempotency key header has precedence over the request_id field
+	if idempotencyKey := req.Header.Get("Idempotency-Key"); idempotencyKey != "" {
+		credit.RequestID = &idempotencyKey
+	}
+
+	// the tenant of the caller
+	credit.TenantID, err = h.requestTenant(req, credit.TenantID)
+	if err != nil {
+		apiError = core_apiError.NewAPIError(err, tenantStatus(err))
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+
+	telemetry.SetCreditAttributes(span, &credit)
+
 	//call service
 	res, err := h.workerService.AddCredit(req.Context(), &credit)
 	if err != nil {
 		switch err {
 		case erro.ErrNotFound:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+			apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+		case erro.ErrHTTPForbiden:
+			apiError = core_apiError.NewAPIError(err, http.StatusForbidden)
+		case erro.ErrInvalidParameter:
+			apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
 		case erro.ErrTransInvalid:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusConflict)
-		case erro.ErrInvalidAmount:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusConflict)
+			apiError = core_apiError.NewAPIError(err, http.StatusConflict)
 		case erro.ErrInvalidAmount:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusConflict)	
+			apiError = core_apiError.NewAPIError(err, http.StatusConflict)
+		case erro.ErrIdempotencyConflict:
+			apiError = core_apiError.NewAPIError(err, http.StatusConflict)	
+		case erro.ErrServiceUnavailable:
+			apiError = core_apiError.NewAPIError(err, http.StatusServiceUnavailable)
 		default:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
+			apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
 		}
-		return &core_apiError
+		h.creditMetrics.record(req.Context(), &credit, apiError.StatusCode)
+		telemetry.RecordError(span, &apiError)
+		return &apiError
 	}
+	h.creditMetrics.record(req.Context(), res, http.StatusOK)
 	
 	return core_json.WriteJSON(rw, http.StatusOK, res)
 }
 
+// About read a batch of credits, a JSON array or NDJSON (one credit per line)
+// The items are streamed, the read stops at the first item above maxItems (0 without limit)
+func decodeBatch(req *http.Request, maxItems int) ([]model.AccountStatement, error) {
+	credits := []model.AccountStatement{}
+	reader := bufio.NewReader(req.Body)
+
+	// a JSON array starts with [
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			if err == io.EOF {
+				return credits, nil
+			}
+			return nil, err
+		}
+		if b[0] == ' ' || b[0] == '\t' || b[0] == '\r' || b[0] == '\n' {
+			reader.ReadByte()
+			continue
+		}
+		if b[0] == '[' {
+			return decodeBatchArray(json.NewDecoder(reader), maxItems)
+		}
+		break
+	}
+
+	decoder := json.NewDecoder(reader)
+	for {
+		credit := model.AccountStatement{}
+		err := decoder.Decode(&credit)
+		if err == io.EOF {
+			return credits, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", len(credits), err)
+		}
+		if maxItems > 0 && len(credits) >= maxItems {
+			return nil, erro.ErrBatchTooLarge
+		}
+		credits = append(credits, credit)
+	}
+}
+
+// About read a JSON array of credits item by item
+func decodeBatchArray(decoder *json.Decoder, maxItems int) ([]model.AccountStatement, error) {
+	credits := []model.AccountStatement{}
+
+	// the opening [
+	_, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	for decoder.More() {
+		if maxItems > 0 && len(credits) >= maxItems {
+			return nil, erro.ErrBatchTooLarge
+		}
+		credit := model.AccountStatement{}
+		err = decoder.Decode(&credit)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", len(credits), err)
+		}
+		credits = append(credits, credit)
+	}
+
+	// the closing ]
+	_, err = decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	return credits, nil
+}
+
+// About add a batch of credits
+func (h *HttpRouters) AddCreditBatch(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddCreditBatch").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	//trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.AddCreditBatch")
+	defer span.End()
+
+	var apiError coreJson.APIError
+
+	//parameters
+	allOrNothing := false
+	if req.URL.Query().Get("all_or_nothing") != "" {
+		boolVar, err := strconv.ParseBool(req.URL.Query().Get("all_or_nothing"))
+		if err != nil {
+			apiError = core_apiError.NewAPIError(erro.ErrInvalidParameter, http.StatusBadRequest)
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+		allOrNothing = boolVar
+	}
+
+	// prepare body, limited in bytes and items
+	maxItems := 0
+	if h.appServer.BatchConfig != nil {
+		maxItems = h.appServer.BatchConfig.MaxItems
+		if h.appServer.BatchConfig.MaxBytes > 0 {
+			req.Body = http.MaxBytesReader(rw, req.Body, h.appServer.BatchConfig.MaxBytes)
+		}
+	}
+	credits, err := decodeBatch(req, maxItems)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			apiError = core_apiError.NewAPIError(err, http.StatusRequestEntityTooLarge)
+		} else {
+			apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		}
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	defer req.Body.Close()
+
+	// every credit of the batch is of the caller tenant
+	for i := range credits {
+		credits[i].TenantID, err = h.requestTenant(req, credits[i].TenantID)
+		if err != nil {
+			apiError = core_apiError.NewAPIError(fmt.Errorf("item %d: %w", i, err), tenantStatus(err))
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+	}
+
+	//call service
+	res, err := h.workerService.AddCreditBatch(req.Context(), credits, allOrNothing)
+	if err != nil {
+		switch err {
+		case erro.ErrBatchEmpty, erro.ErrBatchTooLarge:
+			apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		default:
+			apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
+		}
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	for _, item := range res.Items {
+		statusCode := http.StatusOK
+		if item.Status != "OK" {
+			statusCode = http.StatusUnprocessableEntity
+		}
+		h.creditMetrics.record(req.Context(), &credits[item.Index], statusCode)
+	}
+
+	// a all-or-nothing batch with a invalid credit was not applied
+	if res.AllOrNothing && res.Failed > 0 {
+		return core_json.WriteJSON(rw, http.StatusUnprocessableEntity, res)
+	}
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
 // About list all credit 
 func (h *HttpRouters) ListCredit(rw http.ResponseWriter, req *http.Request) error {
 	childLogger.Info().Str("func","ListCredit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
@@ -98,6 +326,8 @@ func (h *HttpRouters) ListCredit(rw http.ResponseWriter, req *http.Request) erro
 	span := tracerProvider.Span(req.Context(), "adapter.api.ListCredit")
 	defer span.End()
 
+	var apiError coreJson.APIError
+
 	//parameters
 	vars := mux.Vars(req)
 	varID := vars["id"]
@@ -105,21 +335,130 @@ func (h *HttpRouters) ListCredit(rw http.ResponseWriter, req *http.Request) erro
 	credit := model.AccountStatement{}
 	credit.AccountID = varID
 
+	tenantID, err := h.requestTenant(req, "")
+	if err != nil {
+		apiError = core_apiError.NewAPIError(err, tenantStatus(err))
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	credit.TenantID = tenantID
+
+	// pagination and filters
+	params := req.URL.Query()
+	listFilter := model.ListFilter{}
+	listFilter.Currency = params.Get("currency")
+
+	if params.Get("limit") != "" {
+		limit, err := strconv.Atoi(params.Get("limit"))
+		if err != nil {
+			apiError = core_apiError.NewAPIError(erro.ErrInvalidParameter, http.StatusBadRequest)
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+		listFilter.Limit = limit
+	}
+	if params.Get("cursor") != "" {
+		cursor := params.Get("cursor")
+		listFilter.Cursor = &cursor
+	}
+	if params.Get("amount_min") != "" {
+		amount, err := model.ParseMoney(params.Get("amount_min"))
+		if err != nil {
+			apiError = core_apiError.NewAPIError(erro.ErrInvalidParameter, http.StatusBadRequest)
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+		listFilter.AmountMin = &amount
+	}
+	if params.Get("amount_max") != "" {
+		amount, err := model.ParseMoney(params.Get("amount_max"))
+		if err != nil {
+			apiError = core_apiError.NewAPIError(erro.ErrInvalidParameter, http.StatusBadRequest)
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+		listFilter.AmountMax = &amount
+	}
+
+	listFilter.Metadata = parseMetadata(params)
+
+	telemetry.SetCreditAttributes(span, &credit)
+
 	// call service
-	res, err := h.workerService.ListCredit(req.Context(), &credit)
+	res, err := h.workerService.ListCredit(req.Context(), &credit, &listFilter)
 	if err != nil {
 		switch err {
 		case erro.ErrNotFound:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+			apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+		case erro.ErrHTTPForbiden:
+			apiError = core_apiError.NewAPIError(err, http.StatusForbidden)
+		case erro.ErrInvalidParameter:
+			apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
 		default:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
+			apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
 		}
-		return &core_apiError
+		telemetry.RecordError(span, &apiError)
+		return &apiError
 	}
 	
 	return core_json.WriteJSON(rw, http.StatusOK, res)
 }
 
+// a IANA time zone name, Area/Location (America/Sao_Paulo) or a single name (UTC, EST5EDT)
+var ianaZoneName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_+-]*(/[A-Za-z0-9_+-]+)*$`)
+
+// About parse the tz parameter, default UTC. Local (the time zone of the pod) is refused,
+// the name also goes to the database (date_trunc) and must be a IANA one
+func parseLocation(value string) (*time.Location, error) {
+	if value == "" {
+		return time.UTC, nil
+	}
+	if value == "Local" || !ianaZoneName.MatchString(value) {
+		return nil, erro.ErrInvalidTimeZone
+	}
+
+	location, err := time.LoadLocation(value)
+	if err != nil {
+		return nil, erro.ErrInvalidTimeZone
+	}
+
+	return location, nil
+}
+
+// About parse a date (2024-07-24) in a time zone or a RFC 3339 timestamp.
+// A date as the end of a range means the whole day (next day 00:00, exclusive)
+func parseDate(value string, location *time.Location, endOfRange bool) (*time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return &date, nil
+	}
+
+	convertDate, err := core_tools.ConvertToDate(value)
+	if err != nil {
+		return nil, err
+	}
+	date := time.Date(convertDate.Year(), convertDate.Month(), convertDate.Day(), 0, 0, 0, 0, location)
+	if endOfRange {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return &date, nil
+}
+
+// About the metadata filter, each metadata.<key>=<value> parameter must match
+func parseMetadata(params url.Values) map[string]string {
+	metadata := make(map[string]string)
+	for param, values := range params {
+		key, found := strings.CutPrefix(param, "metadata.")
+		if found && key != "" && len(values) > 0 {
+			metadata[key] = values[0]
+		}
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
 // About list all credits per date
 func (h *HttpRouters) ListCreditPerDate(rw http.ResponseWriter, req *http.Request) error {
 	childLogger.Info().Str("func","ListCreditPerDate").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
@@ -127,32 +466,577 @@ func (h *HttpRouters) ListCreditPerDate(rw http.ResponseWriter, req *http.Reques
 	span := tracerProvider.Span(req.Context(), "adapter.api.ListCreditPerDate")
 	defer span.End()
 
+	var apiError coreJson.APIError
+
 	// parameter
 	params := req.URL.Query()
 	varAcc := params.Get("account")
-	varDate := params.Get("date_start")
 
 	credit := model.AccountStatement{}
 	credit.AccountID = varAcc
 
-	convertDate, err := core_tools.ConvertToDate(varDate)
+	tenantID, err := h.requestTenant(req, "")
+	if err != nil {
+		apiError = core_apiError.NewAPIError(err, tenantStatus(err))
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	credit.TenantID = tenantID
+
+	// the day boundaries are computed in the customer time zone (default UTC)
+	location, err := parseLocation(params.Get("tz"))
+	if err != nil {
+		apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+
+	listFilter := model.ListFilter{}
+	dateStart, err := parseDate(params.Get("date_start"), location, false)
 	if err != nil {
-		core_apiError = core_apiError.NewAPIError(erro.ErrUnmarshal, http.StatusBadRequest)
-		return &core_apiError
+		apiError = core_apiError.NewAPIError(erro.ErrUnmarshal, http.StatusBadRequest)
+		telemetry.RecordError(span, &apiError)
+		return &apiError
 	}
-	credit.ChargeAt = *convertDate
+	listFilter.DateStart = *dateStart
+
+	if params.Get("date_end") != "" {
+		dateEnd, err := parseDate(params.Get("date_end"), location, true)
+		if err != nil {
+			apiError = core_apiError.NewAPIError(erro.ErrUnmarshal, http.StatusBadRequest)
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+		listFilter.DateEnd = *dateEnd
+	}
+
+	listFilter.Metadata = parseMetadata(params)
+
+	telemetry.SetCreditAttributes(span, &credit)
 
 	//service
-	res, err := h.workerService.ListCreditPerDate(req.Context(), &credit)
+	res, err := h.workerService.ListCreditPerDate(req.Context(), &credit, &listFilter)
 	if err != nil {
 		switch err {
 		case erro.ErrNotFound:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+			apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+		case erro.ErrHTTPForbiden:
+			apiError = core_apiError.NewAPIError(err, http.StatusForbidden)
+		case erro.ErrInvalidDateRange:
+			apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
 		default:
-			core_apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
+			apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
 		}
-		return &core_apiError
+		telemetry.RecordError(span, &apiError)
+		return &apiError
 	}
 	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About export the credits of an account (csv, ofx or camt053), the rows are streamed from the database
+func (h *HttpRouters) ExportCredit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ExportCredit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+	//Trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.ExportCredit")
+	defer span.End()
+
+	var apiError coreJson.APIError
+
+	//parameters
+	vars := mux.Vars(req)
+	varID := vars["id"]
+
+	credit := model.AccountStatement{}
+	credit.AccountID = varID
+
+	tenantID, err := h.requestTenant(req, "")
+	if err != nil {
+		apiError = core_apiError.NewAPIError(err, tenantStatus(err))
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	credit.TenantID = tenantID
+
+	params := req.URL.Query()
+	format, ok := exportFormats[params.Get("format")]
+	if !ok {
+		apiError = core_apiError.NewAPIError(erro.ErrExportFormat, http.StatusBadRequest)
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+
+	// the filters of the list, plus the date range of the list per date
+	listFilter := model.ListFilter{}
+	listFilter.Currency = params.Get("currency")
+	if format.NeedCurrency && listFilter.Currency == "" {
+		apiError = core_apiError.NewAPIError(erro.ErrExportCurrency, http.StatusBadRequest)
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	if params.Get("amount_min") != "" {
+		amount, err := model.ParseMoney(params.Get("amount_min"))
+		if err != nil {
+			apiError = core_apiError.NewAPIError(erro.ErrInvalidParameter, http.StatusBadRequest)
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+		listFilter.AmountMin = &amount
+	}
+	if params.Get("amount_max") != "" {
+		amount, err := model.ParseMoney(params.Get("amount_max"))
+		if err != nil {
+			apiError = core_apiError.NewAPIError(erro.ErrInvalidParameter, http.StatusBadRequest)
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+		listFilter.AmountMax = &amount
+	}
+
+	location, err := parseLocation(params.Get("tz"))
+	if err != nil {
+		apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	if params.Get("date_start") != "" {
+		dateStart, err := parseDate(params.Get("date_start"), location, false)
+		if err != nil {
+			apiError = core_apiError.NewAPIError(erro.ErrUnmarshal, http.StatusBadRequest)
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+		listFilter.DateStart = *dateStart
+	}
+	if params.Get("date_end") != "" {
+		dateEnd, err := parseDate(params.Get("date_end"), location, true)
+		if err != nil {
+			apiError = core_apiError.NewAPIError(erro.ErrUnmarshal, http.StatusBadRequest)
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+		listFilter.DateEnd = *dateEnd
+	}
+
+	listFilter.Metadata = parseMetadata(params)
+
+	// prepare the writer, nothing is sent before the first row
+	export := statementExport{	AccountID: varID,
+								CreatedAt: time.Now(),
+								ListFilter: &listFilter,
+	}
+	response := exportResponse{ rw: rw,
+								format: format,
+								filename: exportFilename(&export, format),
+	}
+	writer := format.NewWriter(&response, &export)
+
+	telemetry.SetCreditAttributes(span, &credit)
+
+	//service
+	rows := 0
+	err = h.workerService.ExportCredit(req.Context(), &credit, &listFilter, func(res *model.AccountStatement) error {
+		err := writer.Write(res)
+		if err != nil {
+			return err
+		}
+		rows++
+		if rows % exportFlushRows == 0 {
+			err = writer.Flush()
+			if err != nil {
+				return err
+			}
+			response.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		if response.started {
+			// the status is already sent, abort the response so the client sees a truncated file
+			childLogger.Error().Err(err).Int("rows", rows).Msg("error export credit")
+			telemetry.RecordError(span, err)
+			panic(http.ErrAbortHandler)
+		}
+		switch err {
+		case erro.ErrNotFound:
+			apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+		case erro.ErrHTTPForbiden:
+			apiError = core_apiError.NewAPIError(err, http.StatusForbidden)
+		case erro.ErrInvalidDateRange:
+			apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		default:
+			apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
+		}
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	response.Flush()
+
+	return nil
+}
+
+// About the totals of the credits of an account
+func (h *HttpRouters) SummaryCredit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","SummaryCredit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+	//Trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.SummaryCredit")
+	defer span.End()
+
+	var apiError coreJson.APIError
+
+	//parameters
+	vars := mux.Vars(req)
+	varID := vars["account_id"]
+
+	credit := model.AccountStatement{}
+	credit.AccountID = varID
+
+	tenantID, err := h.requestTenant(req, "")
+	if err != nil {
+		apiError = core_apiError.NewAPIError(err, tenantStatus(err))
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	credit.TenantID = tenantID
+
+	params := req.URL.Query()
+	summaryFilter := model.SummaryFilter{}
+	summaryFilter.GroupBy = params.Get("group_by")
+
+	// the periods are computed in the customer time zone (default UTC)
+	summaryFilter.Location, err = parseLocation(params.Get("tz"))
+	if err != nil {
+		apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	if params.Get("from") != "" {
+		dateStart, err := parseDate(params.Get("from"), summaryFilter.Location, false)
+		if err != nil {
+			apiError = core_apiError.NewAPIError(erro.ErrUnmarshal, http.StatusBadRequest)
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+		summaryFilter.DateStart = *dateStart
+	}
+	if params.Get("to") != "" {
+		dateEnd, err := parseDate(params.Get("to"), summaryFilter.Location, true)
+		if err != nil {
+			apiError = core_apiError.NewAPIError(erro.ErrUnmarshal, http.StatusBadRequest)
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+		summaryFilter.DateEnd = *dateEnd
+	}
+
+	telemetry.SetCreditAttributes(span, &credit)
+
+	//service
+	res, err := h.workerService.SummaryCredit(req.Context(), &credit, &summaryFilter)
+	if err != nil {
+		switch err {
+		case erro.ErrNotFound:
+			apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+		case erro.ErrHTTPForbiden:
+			apiError = core_apiError.NewAPIError(err, http.StatusForbidden)
+		case erro.ErrInvalidDateRange, erro.ErrInvalidGroupBy:
+			apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		default:
+			apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
+		}
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About reverse a credit
+func (h *HttpRouters) AddReversal(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddReversal").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	//trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.AddReversal")
+	defer span.End()
+
+	var apiError coreJson.APIError
+
+	//parameters
+	vars := mux.Vars(req)
+	varID := vars["transaction_id"]
+
+	// prepare body (account_id is required, amount and obs are optional)
+	reversal := model.AccountStatement{}
+	err := json.NewDecoder(req.Body).Decode(&reversal)
+    if err != nil {
+		apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+    }
+	defer req.Body.Close()
+
+	reversal.ReversalOf = &varID
+	// the idempotency key header has precedence over the request_id field
+	if idempotencyKey := req.Header.Get("Idempotency-Key"); idempotencyKey != "" {
+		reversal.RequestID = &idempotencyKey
+	}
+
+	// the tenant of the caller
+	reversal.TenantID, err = h.requestTenant(req, reversal.TenantID)
+	if err != nil {
+		apiError = core_apiError.NewAPIError(err, tenantStatus(err))
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+
+	telemetry.SetCreditAttributes(span, &reversal)
+
+	//call service
+	res, err := h.workerService.AddReversal(req.Context(), &reversal)
+	if err != nil {
+		switch err {
+		case erro.ErrNotFound:
+			apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+		case erro.ErrHTTPForbiden:
+			apiError = core_apiError.NewAPIError(err, http.StatusForbidden)
+		case erro.ErrTransInvalid:
+			apiError = core_apiError.NewAPIError(err, http.StatusConflict)
+		case erro.ErrInvalidAmount:
+			apiError = core_apiError.NewAPIError(err, http.StatusConflict)
+		case erro.ErrAlreadyReversed:
+			apiError = core_apiError.NewAPIError(err, http.StatusConflict)
+		case erro.ErrIdempotencyConflict:
+			apiError = core_apiError.NewAPIError(err, http.StatusConflict)
+		default:
+			apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
+		}
+		reversal.Type = "CREDIT-REVERSAL"
+		h.creditMetrics.record(req.Context(), &reversal, apiError.StatusCode)
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	h.creditMetrics.record(req.Context(), res, http.StatusOK)
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About reserve a pending credit
+func (h *HttpRouters) AddHold(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddHold").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	//trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.AddHold")
+	defer span.End()
+
+	var apiError coreJson.APIError
+
+	// prepare body
+	hold := model.Hold{}
+	err := json.NewDecoder(req.Body).Decode(&hold)
+    if err != nil {
+		apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+    }
+	defer req.Body.Close()
+
+	// the tenant of the caller
+	hold.TenantID, err = h.requestTenant(req, hold.TenantID)
+	if err != nil {
+		apiError = core_apiError.NewAPIError(err, tenantStatus(err))
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+
+	telemetry.SetHoldAttributes(span, &hold)
+
+	//call service
+	res, err := h.workerService.AddHold(req.Context(), &hold)
+	if err != nil {
+		switch err {
+		case erro.ErrNotFound:
+			apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+		case erro.ErrHTTPForbiden:
+			apiError = core_apiError.NewAPIError(err, http.StatusForbidden)
+		case erro.ErrInvalidAmount:
+			apiError = core_apiError.NewAPIError(err, http.StatusConflict)
+		case erro.ErrInvalidParameter:
+			apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		default:
+			apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
+		}
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About capture or void a hold
+func (h *HttpRouters) UpdateHold(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","UpdateHold").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	//trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.UpdateHold")
+	defer span.End()
+
+	var apiError coreJson.APIError
+
+	//parameters
+	vars := mux.Vars(req)
+	varID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apiError = core_apiError.NewAPIError(erro.ErrInvalidParameter, http.StatusBadRequest)
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+
+	hold := model.Hold{}
+	hold.ID = varID
+
+	// a hold of another tenant is not found
+	hold.TenantID, err = h.requestTenant(req, "")
+	if err != nil {
+		apiError = core_apiError.NewAPIError(err, tenantStatus(err))
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+
+	telemetry.SetHoldAttributes(span, &hold)
+
+	//call service
+	var res *model.Hold
+	switch vars["action"] {
+	case "capture":
+		res, err = h.workerService.CaptureHold(req.Context(), &hold)
+	case "void":
+		res, err = h.workerService.VoidHold(req.Context(), &hold)
+	default:
+		err = erro.ErrInvalidAction
+	}
+	if err != nil {
+		switch err {
+		case erro.ErrNotFound:
+			apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+		case erro.ErrHTTPForbiden:
+			apiError = core_apiError.NewAPIError(err, http.StatusForbidden)
+		case erro.ErrInvalidAction, erro.ErrInvalidParameter:
+			apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		case erro.ErrHoldNotPending, erro.ErrHoldExpired:
+			apiError = core_apiError.NewAPIError(err, http.StatusConflict)
+		default:
+			apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
+		}
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	// the capture adds the credit of the hold
+	if res.Credit != nil {
+		h.creditMetrics.record(req.Context(), res.Credit, http.StatusOK)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list the circuit breakers
+func (h *HttpRouters) ListCircuitBreaker(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListCircuitBreaker").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	res, err := h.workerService.ListCircuitBreaker(req.Context())
+	if err != nil {
+		apiError := core_apiError.NewAPIError(err, http.StatusInternalServerError)
+		return &apiError
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About force the state of a circuit breaker
+func (h *HttpRouters) SetCircuitBreaker(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","SetCircuitBreaker").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	var apiError coreJson.APIError
+
+	//parameters
+	vars := mux.Vars(req)
+	varName := vars["name"]
+	varAction := vars["action"]
+
+	res, err := h.workerService.SetCircuitBreaker(req.Context(), varName, varAction)
+	if err != nil {
+		switch err {
+		case erro.ErrNotFound:
+			apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+		case erro.ErrInvalidAction:
+			apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		default:
+			apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
+		}
+		return &apiError
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get a credit of the caller tenant by id or transaction id
+func (h *HttpRouters) GetCredit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetCredit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(req.Context(), "adapter.api.GetCredit")
+	defer span.End()
+
+	var apiError coreJson.APIError
+
+	//parameters
+	vars := mux.Vars(req)
+
+	credit := model.AccountStatement{}
+
+	tenantID, err := h.requestTenant(req, "")
+	if err != nil {
+		apiError = core_apiError.NewAPIError(err, tenantStatus(err))
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+	credit.TenantID = tenantID
+
+	if varID, ok := vars["id"]; ok {
+		id, err := strconv.Atoi(varID)
+		if err != nil {
+			apiError = core_apiError.NewAPIError(erro.ErrInvalidParameter, http.StatusBadRequest)
+			telemetry.RecordError(span, &apiError)
+			return &apiError
+		}
+		credit.ID = id
+	}
+	if varTransactionID, ok := vars["transaction_id"]; ok {
+		credit.TransactionID = &varTransactionID
+	}
+
+	telemetry.SetCreditAttributes(span, &credit)
+
+	// call service
+	res, err := h.workerService.GetCredit(req.Context(), &credit)
+	if err != nil {
+		switch err {
+		case erro.ErrNotFound:
+			apiError = core_apiError.NewAPIError(err, http.StatusNotFound)
+		case erro.ErrHTTPForbiden:
+			apiError = core_apiError.NewAPIError(err, http.StatusForbidden)
+		case erro.ErrInvalidParameter:
+			apiError = core_apiError.NewAPIError(err, http.StatusBadRequest)
+		case erro.ErrCircuitOpen:
+			apiError = core_apiError.NewAPIError(err, http.StatusServiceUnavailable)
+		default:
+			apiError = core_apiError.NewAPIError(err, http.StatusInternalServerError)
+		}
+		telemetry.RecordError(span, &apiError)
+		return &apiError
+	}
+
 	return core_json.WriteJSON(rw, http.StatusOK, res)
 }
\ No newline at end of file
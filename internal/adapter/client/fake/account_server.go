@@ -0,0 +1,238 @@
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-credit/internal/core/model"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+var childLogger = log.With().Str("component","go-credit").Str("package","internal.adapter.client.fake").Logger()
+
+// a scripted response, used once by the next request of the route
+type Response struct {
+	StatusCode	int
+	Body		interface{}
+	Latency		time.Duration
+}
+
+// httptest server with the go-account (and go-fund-transfer) routes used by go-credit
+// the routes are named as the endpoints: account-get, account-get-id, account-balance, fund-transfer
+type AccountServer struct {
+	Server		*httptest.Server
+	mutex		sync.Mutex
+	accounts	map[string]model.Account
+	scripts		map[string][]Response
+	requests	map[string]int
+	latency		time.Duration
+	balances	[]model.AccountStatement
+	requestIDs	map[string]bool
+	transfers	[]model.Transfer
+}
+
+// About start a fake go-account
+func NewAccountServer() *AccountServer {
+	childLogger.Info().Str("func","NewAccountServer").Send()
+
+	f := &AccountServer{
+		accounts: make(map[string]model.Account),
+		scripts: make(map[string][]Response),
+		requests: make(map[string]int),
+		requestIDs: make(map[string]bool),
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/get/id/{id}", f.handle("account-get-id", f.getAccountByID)).Methods(http.MethodGet)
+	router.HandleFunc("/get/{account_id}", f.handle("account-get", f.getAccount)).Methods(http.MethodGet)
+	router.HandleFunc("/add/accountBalance", f.handle("account-balance", f.addAccountBalance)).Methods(http.MethodPost)
+	router.HandleFunc("/creditTransferEvent", f.handle("fund-transfer", f.creditTransfer)).Methods(http.MethodPost)
+
+	f.Server = httptest.NewServer(router)
+
+	return f
+}
+
+// About stop the server
+func (f *AccountServer) Close() {
+	f.Server.Close()
+}
+
+// About the endpoints configuration pointing to the server
+func (f *AccountServer) Endpoints() map[string]model.ApiService {
+	paths := map[string]struct{ path, method string }{
+		"account-get": {"/get", http.MethodGet},
+		"account-get-id": {"/get/id", http.MethodGet},
+		"account-balance": {"/add/accountBalance", http.MethodPost},
+		"fund-transfer": {"/creditTransferEvent", http.MethodPost},
+	}
+
+	apiService := make(map[string]model.ApiService)
+	for name, route := range paths {
+		apiService[name] = model.ApiService{	Name: name,
+												Url: f.Server.URL + route.path,
+												Method: route.method,
+												Header_x_apigw_api_id: "fake",
+												Timeout: 5,
+		}
+	}
+	return apiService
+}
+
+// About add a account
+func (f *AccountServer) AddAccount(account model.Account) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.accounts[account.AccountID] = account
+}
+
+// About the latency of every response
+func (f *AccountServer) SetLatency(latency time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.latency = latency
+}
+
+// About queue responses for the next requests of a route
+func (f *AccountServer) Script(route string, responses ...Response) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.scripts[route] = append(f.scripts[route], responses...)
+}
+
+// About fail the next requests of a route with a status code
+func (f *AccountServer) Fail(route string, statusCode int, times int) {
+	for i := 0; i < times; i++ {
+		f.Script(route, Response{StatusCode: statusCode})
+	}
+}
+
+// About the number of requests received by a route
+func (f *AccountServer) Requests(route string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.requests[route]
+}
+
+// About the account statements applied by account-balance, a X-Request-Id already received is applied once
+func (f *AccountServer) Balances() []model.AccountStatement {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]model.AccountStatement{}, f.balances...)
+}
+
+// About the transfers received by fund-transfer
+func (f *AccountServer) Transfers() []model.Transfer {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]model.Transfer{}, f.transfers...)
+}
+
+// About count the request, apply latency and the scripted response, otherwise call the route handler
+func (f *AccountServer) handle(route string, handler func(rw http.ResponseWriter, req *http.Request)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		f.mutex.Lock()
+		f.requests[route] = f.requests[route] + 1
+		latency := f.latency
+		var script *Response
+		if len(f.scripts[route]) > 0 {
+			script = &f.scripts[route][0]
+			f.scripts[route] = f.scripts[route][1:]
+		}
+		f.mutex.Unlock()
+
+		if script != nil {
+			latency = latency + script.Latency
+		}
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-req.Context().Done():
+				return
+			}
+		}
+
+		if script != nil && script.StatusCode != 0 {
+			writeJSON(rw, script.StatusCode, script.Body)
+			return
+		}
+		handler(rw, req)
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, statusCode int, body interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(statusCode)
+	if body != nil {
+		json.NewEncoder(rw).Encode(body)
+	}
+}
+
+func (f *AccountServer) getAccount(rw http.ResponseWriter, req *http.Request) {
+	f.mutex.Lock()
+	account, ok := f.accounts[mux.Vars(req)["account_id"]]
+	f.mutex.Unlock()
+
+	if !ok {
+		writeJSON(rw, http.StatusNotFound, map[string]string{"msg": "item not found"})
+		return
+	}
+	writeJSON(rw, http.StatusOK, account)
+}
+
+func (f *AccountServer) getAccountByID(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(req)["id"])
+	if err != nil {
+		writeJSON(rw, http.StatusBadRequest, map[string]string{"msg": err.Error()})
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, account := range f.accounts {
+		if account.ID == id {
+			writeJSON(rw, http.StatusOK, account)
+			return
+		}
+	}
+	writeJSON(rw, http.StatusNotFound, map[string]string{"msg": "item not found"})
+}
+
+func (f *AccountServer) addAccountBalance(rw http.ResponseWriter, req *http.Request) {
+	credit := model.AccountStatement{}
+	if err := json.NewDecoder(req.Body).Decode(&credit); err != nil {
+		writeJSON(rw, http.StatusBadRequest, map[string]string{"msg": err.Error()})
+		return
+	}
+
+	// the contract of go-account, a redelivery of the same request id is not applied again
+	request_id := req.Header.Get("X-Request-Id")
+	f.mutex.Lock()
+	if request_id == "" || !f.requestIDs[request_id] {
+		f.requestIDs[request_id] = true
+		f.balances = append(f.balances, credit)
+	}
+	f.mutex.Unlock()
+
+	writeJSON(rw, http.StatusOK, credit)
+}
+
+func (f *AccountServer) creditTransfer(rw http.ResponseWriter, req *http.Request) {
+	transfer := model.Transfer{}
+	if err := json.NewDecoder(req.Body).Decode(&transfer); err != nil {
+		writeJSON(rw, http.StatusBadRequest, map[string]string{"msg": err.Error()})
+		return
+	}
+
+	f.mutex.Lock()
+	f.transfers = append(f.transfers, transfer)
+	f.mutex.Unlock()
+
+	writeJSON(rw, http.StatusOK, transfer)
+}
@@ -0,0 +1,230 @@
+package client
+
+import(
+	"fmt"
+	"time"
+	"math/rand"
+	"context"
+	"net/http"
+	"strconv"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/core/service"
+	"github.com/go-credit/internal/infra/circuitbreaker"
+	"github.com/go-credit/internal/infra/telemetry"
+	go_core_observ "github.com/eliezerraj/go-core/observability"
+	go_core_api "github.com/eliezerraj/go-core/api"
+
+	"github.com/rs/zerolog/log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var childLogger = log.With().Str("component","go-credit").Str("package","internal.adapter.client").Logger()
+
+var tracerProvider go_core_observ.TracerProvider
+var apiService go_core_api.ApiService
+
+var _ service.AccountClient = (*RestClient)(nil)
+var _ service.TransferClient = (*RestClient)(nil)
+
+// rest client of go-account and go-fund-transfer, each endpoint protected by its circuit breaker
+type RestClient struct {
+	apiService		map[string]model.ApiService
+	circuitBreakers	*circuitbreaker.CircuitBreakers
+	requestDuration	metric.Float64Histogram
+}
+
+// About create a rest client
+func NewRestClient(	apiService map[string]model.ApiService,
+					circuitBreakers *circuitbreaker.CircuitBreakers) *RestClient{
+	childLogger.Info().Str("func","NewRestClient").Send()
+
+	meter := otel.Meter("go-credit")
+	requestDuration, err := meter.Float64Histogram("downstream_request_duration",
+									metric.WithDescription("duration of the calls to the downstream endpoints (each attempt)"),
+									metric.WithUnit("s"),
+									metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create downstream_request_duration metric")
+	}
+
+	return &RestClient{
+		apiService: apiService,
+		circuitBreakers: circuitBreakers,
+		requestDuration: requestDuration,
+	}
+}
+
+// About record the duration of a call, a call without response (timeout, connection) has the status code 503
+func (r *RestClient) recordDuration(ctx context.Context, name string, statusCode int, start time.Time) {
+	if r.requestDuration == nil {
+		return
+	}
+	r.requestDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(	attribute.String("endpoint", name),
+																						attribute.Int("status_code", statusCode)))
+}
+
+// About handle/convert http status code
+func errorStatusCode(statusCode int) error{
+	var err error
+	switch statusCode {
+	case http.StatusUnauthorized:
+		err = erro.ErrUnauthorized
+	case http.StatusForbidden:
+		err = erro.ErrHTTPForbiden
+	case http.StatusNotFound:
+		err = erro.ErrNotFound
+	default:
+		err = erro.ErrServer
+	}
+	return err
+}
+
+// About the wait before a retry, linear (100ms per attempt) with up to 100ms of jitter
+func retryBackoff(attempt int) time.Duration {
+	backoff := time.Duration(attempt * 100) * time.Millisecond
+	jitter := time.Duration(rand.Int63n(int64(100 * time.Millisecond)))
+	return backoff + jitter
+}
+
+// About call a downstream endpoint (by name) protected by its circuit breaker
+func (r *RestClient) callApi(ctx context.Context,
+							name string,
+							path string,
+							trace_id string,
+							body interface{}) (interface{}, error){
+	endpoint, ok := r.apiService[name]
+	if !ok {
+		childLogger.Error().Str("endpoint", name).Msg("endpoint not configured")
+		return nil, erro.ErrServer
+	}
+
+	return r.circuitBreakers.Execute(name, func() (interface{}, error) {
+		var err error
+		for attempt := 0; attempt <= endpoint.Retry; attempt++ {
+			if attempt > 0 {
+				childLogger.Warn().Str("endpoint", name).Int("attempt", attempt).Err(err).Msg("retrying call api")
+				// the wait stops when the request is canceled or times out
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(retryBackoff(attempt)):
+				}
+			}
+
+			start := time.Now()
+			ctxTimeout, cancel := context.WithTimeout(ctx, time.Duration(endpoint.Timeout) * time.Second)
+			res_payload, statusCode, errCall := apiService.CallApi(ctxTimeout,
+																endpoint.Url + path,
+																endpoint.Method,
+																&endpoint.Header_x_apigw_api_id,
+																nil,
+																&trace_id,
+																body)
+			cancel()
+			r.recordDuration(ctx, name, statusCode, start)
+			if errCall == nil {
+				return res_payload, nil
+			}
+
+			// only a server side error is retried
+			err = errorStatusCode(statusCode)
+			if err != erro.ErrServer {
+				return nil, err
+			}
+		}
+		return nil, err
+	})
+}
+
+// About parse the account of a response
+func parseAccount(res_payload interface{}) (*model.Account, error){
+	jsonString, err  := json.Marshal(res_payload)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	var account_parsed model.Account
+	json.Unmarshal(jsonString, &account_parsed)
+
+	return &account_parsed, nil
+}
+
+// About get a account by account id from go-account
+func (r *RestClient) GetAccount(ctx context.Context, accountID string) (*model.Account, error){
+	childLogger.Info().Str("func","GetAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("account_id", accountID).Send()
+
+	// Trace, the call is a child of the span (the propagators inject its context)
+	ctx, span := tracerProvider.SpanCtx(ctx, "client.GetAccount")
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+	defer span.End()
+	telemetry.SetDownstream(span, "account-get")
+	span.SetAttributes(attribute.String("account_id", accountID))
+
+	res_payload, err := r.callApi(ctx, "account-get", "/" + accountID, trace_id, nil)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return nil, err
+	}
+
+	return parseAccount(res_payload)
+}
+
+// About get a account by id (pk) from go-account
+func (r *RestClient) GetAccountByID(ctx context.Context, id int) (*model.Account, error){
+	childLogger.Info().Str("func","GetAccountByID").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("id", id).Send()
+
+	// Trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "client.GetAccountByID")
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+	defer span.End()
+	telemetry.SetDownstream(span, "account-get-id")
+
+	res_payload, err := r.callApi(ctx, "account-get-id", "/" + strconv.Itoa(id), trace_id, nil)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return nil, err
+	}
+
+	return parseAccount(res_payload)
+}
+
+// About add (POST/AddFundBalanceAccount) the account statement to the go-account balance
+func (r *RestClient) AddAccountBalance(ctx context.Context, credit *model.AccountStatement, requestID string) error{
+	childLogger.Info().Str("func","AddAccountBalance").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("request_id", requestID).Send()
+
+	// Trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "client.AddAccountBalance")
+	defer span.End()
+	telemetry.SetDownstream(span, "account-balance")
+	telemetry.SetCreditAttributes(span, credit)
+
+	_, err := r.callApi(ctx, "account-balance", "", requestID, credit)
+	telemetry.RecordError(span, err)
+
+	return err
+}
+
+// About send a credit to go-fund-transfer (creditTransferEvent)
+func (r *RestClient) CreditTransfer(ctx context.Context, transfer *model.Transfer) error{
+	childLogger.Info().Str("func","CreditTransfer").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	ctx, span := tracerProvider.SpanCtx(ctx, "client.CreditTransfer")
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+	defer span.End()
+	telemetry.SetDownstream(span, "fund-transfer")
+	if transfer.AccountFrom != nil {
+		telemetry.SetCreditAttributes(span, transfer.AccountFrom)
+	}
+
+	_, err := r.callApi(ctx, "fund-transfer", "", trace_id, transfer)
+	telemetry.RecordError(span, err)
+
+	return err
+}
@@ -0,0 +1,153 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/adapter/client"
+	"github.com/go-credit/internal/adapter/client/fake"
+	"github.com/go-credit/internal/infra/circuitbreaker"
+)
+
+// About a rest client on a fake go-account with the account ACC-1, account-get with retry and its breaker
+func newTestClient(t *testing.T, retry int, maxFailures uint32) (*client.RestClient, *fake.AccountServer) {
+	t.Helper()
+
+	accountServer := fake.NewAccountServer()
+	t.Cleanup(accountServer.Close)
+	accountServer.AddAccount(model.Account{ID: 1, AccountID: "ACC-1", TenantID: "TENANT-1"})
+
+	endpoints := accountServer.Endpoints()
+	endpoint := endpoints["account-get"]
+	endpoint.Retry = retry
+	endpoints["account-get"] = endpoint
+
+	circuitBreakers := circuitbreaker.NewCircuitBreakers([]model.CircuitBreakerConfig{{	Name: "account-get",
+																						MaxRequests: 1,
+																						Interval: 60,
+																						Timeout: 60,
+																						MaxFailures: maxFailures,
+	}})
+
+	return client.NewRestClient(endpoints, circuitBreakers), accountServer
+}
+
+func TestGetAccountRetry(t *testing.T) {
+	tests := []struct {
+		name		string
+		statusCode	int
+		failures	int
+		wantErr		error
+		wantRequests	int
+	}{
+		{"5xx retried until success", http.StatusInternalServerError, 2, nil, 3},
+		{"5xx retries exhausted", http.StatusServiceUnavailable, 3, erro.ErrServer, 3},
+		{"404 not retried", http.StatusNotFound, 1, erro.ErrNotFound, 1},
+		{"401 not retried", http.StatusUnauthorized, 1, erro.ErrUnauthorized, 1},
+		{"403 not retried", http.StatusForbidden, 1, erro.ErrHTTPForbiden, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restClient, accountServer := newTestClient(t, 2, 100)
+			accountServer.Fail("account-get", tt.statusCode, tt.failures)
+
+			res, err := restClient.GetAccount(context.Background(), "ACC-1")
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && res.AccountID != "ACC-1" {
+				t.Errorf("account = %+v, want ACC-1", res)
+			}
+			if got := accountServer.Requests("account-get"); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestGetAccountRetryCanceled(t *testing.T) {
+	restClient, _ := newTestClient(t, 5, 100)
+
+	// the call fails without response and the wait of the retry stops on the canceled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := restClient.GetAccount(ctx, "ACC-1")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestErrorStatusCode(t *testing.T) {
+	tests := []struct {
+		statusCode	int
+		wantErr		error
+	}{
+		{http.StatusUnauthorized, erro.ErrUnauthorized},
+		{http.StatusForbidden, erro.ErrHTTPForbiden},
+		{http.StatusNotFound, erro.ErrNotFound},
+		{http.StatusInternalServerError, erro.ErrServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.statusCode), func(t *testing.T) {
+			restClient, accountServer := newTestClient(t, 0, 100)
+			accountServer.Fail("account-get", tt.statusCode, 1)
+
+			_, err := restClient.GetAccount(context.Background(), "ACC-1")
+			if err != tt.wantErr {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerOpen(t *testing.T) {
+	restClient, accountServer := newTestClient(t, 0, 2)
+	ctx := context.Background()
+
+	// a business response (404) does not count as a failure
+	accountServer.Fail("account-get", http.StatusNotFound, 3)
+	for i := 0; i < 3; i++ {
+		_, err := restClient.GetAccount(ctx, "ACC-1")
+		if err != erro.ErrNotFound {
+			t.Fatalf("call %d: err = %v, want %v", i, err, erro.ErrNotFound)
+		}
+	}
+
+	// opens after MaxFailures server errors
+	accountServer.Fail("account-get", http.StatusInternalServerError, 2)
+	for i := 0; i < 2; i++ {
+		_, err := restClient.GetAccount(ctx, "ACC-1")
+		if err != erro.ErrServer {
+			t.Fatalf("failure %d: err = %v, want %v", i, err, erro.ErrServer)
+		}
+	}
+
+	_, err := restClient.GetAccount(ctx, "ACC-1")
+	if err != erro.ErrCircuitOpen {
+		t.Fatalf("err = %v, want %v", err, erro.ErrCircuitOpen)
+	}
+	if got := accountServer.Requests("account-get"); got != 5 {
+		t.Errorf("requests = %d, want 5 (no call while open)", got)
+	}
+}
+
+func TestGetAccountByID(t *testing.T) {
+	restClient, _ := newTestClient(t, 0, 100)
+
+	res, err := restClient.GetAccountByID(context.Background(), 1)
+	if err != nil || res.AccountID != "ACC-1" {
+		t.Fatalf("account = %+v, err = %v, want ACC-1", res, err)
+	}
+
+	_, err = restClient.GetAccountByID(context.Background(), 2)
+	if err != erro.ErrNotFound {
+		t.Errorf("unknown id: err = %v, want %v", err, erro.ErrNotFound)
+	}
+}
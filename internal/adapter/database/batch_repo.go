@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/infra/telemetry"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About add credits in one round trip (pgx batch), the transaction_id is created by the database
+func (w WorkerRepository) AddCreditBatch(ctx context.Context, tx pgx.Tx, credits []model.AccountStatement) ([]model.AccountStatement, error){
+	childLogger.Info().Str("func","AddCreditBatch").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("credits", len(credits)).Send()
+
+	//trace
+	span := tracerProvider.Span(ctx, "database.AddCreditBatch")
+	defer span.End()
+
+	// Prepare
+	charge_at := time.Now()
+
+	// Query e Execute
+	query := `INSERT INTO account_statement (fk_account_id, 
+											type_charge,
+											charged_at, 
+											currency,
+											amount,
+											tenant_id,
+											transaction_id,
+											obs,
+											metadata) 
+			 VALUES($1, $2, $3, $4, $5, $6, uuid_generate_v4()::varchar, $7, $8) RETURNING id, transaction_id`
+
+	batch := &pgx.Batch{}
+	for i := range credits {
+		credits[i].ChargeAt = charge_at
+		batch.Queue(query,	credits[i].FkAccountID,
+							credits[i].Type,
+							credits[i].ChargeAt,
+							credits[i].Currency,
+							credits[i].Amount,
+							credits[i].TenantID,
+							credits[i].Obs,
+							credits[i].Metadata)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for i := range credits {
+		if err := results.QueryRow().Scan(&credits[i].ID, &credits[i].TransactionID); err != nil {
+			results.Close()
+			telemetry.RecordError(span, err)
+			return nil, errors.New(err.Error())
+		}
+	}
+	if err := results.Close(); err != nil {
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+
+	return credits, nil
+}
+
+// About add outbox events in one round trip (pgx batch)
+func (w WorkerRepository) AddOutboxEventBatch(ctx context.Context, tx pgx.Tx, outboxEvents []model.OutboxEvent) ([]model.OutboxEvent, error){
+	childLogger.Info().Str("func","AddOutboxEventBatch").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("events", len(outboxEvents)).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddOutboxEventBatch")
+	defer span.End()
+
+	// Prepare
+	created_at := time.Now()
+
+	// Query e Execute
+	query := `INSERT INTO credit_outbox (aggregate_id,
+										event_type,
+										payload,
+										status,
+										attempts,
+										next_attempt_at,
+										created_at)
+			VALUES($1, $2, $3, $4, 0, $5, $6) RETURNING id`
+
+	batch := &pgx.Batch{}
+	for i := range outboxEvents {
+		outboxEvents[i].CreatedAt = created_at
+		outboxEvents[i].NextAttemptAt = created_at
+		outboxEvents[i].Status = "PENDING"
+		batch.Queue(query,	outboxEvents[i].AggregateID,
+							outboxEvents[i].EventType,
+							outboxEvents[i].Payload,
+							outboxEvents[i].Status,
+							outboxEvents[i].NextAttemptAt,
+							outboxEvents[i].CreatedAt)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for i := range outboxEvents {
+		if err := results.QueryRow().Scan(&outboxEvents[i].ID); err != nil {
+			results.Close()
+			telemetry.RecordError(span, err)
+			return nil, errors.New(err.Error())
+		}
+	}
+	if err := results.Close(); err != nil {
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+
+	return outboxEvents, nil
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"time"
 	"errors"
+	"encoding/json"
 	
 	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/infra/telemetry"
 	go_core_observ "github.com/eliezerraj/go-core/observability"
 	go_core_pg "github.com/eliezerraj/go-core/database/pg"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
 )
 
@@ -24,11 +28,51 @@ type WorkerRepository struct {
 func NewWorkerRepository(databasePGServer *go_core_pg.DatabasePGServer) *WorkerRepository{
 	childLogger.Info().Str("func","NewWorkerRepository").Send()
 
+	registerPoolMetrics(databasePGServer)
+
 	return &WorkerRepository{
 		DatabasePGServer: databasePGServer,
 	}
 }
 
+// About start a transaction
+func (w WorkerRepository) StartTx(ctx context.Context) (pgx.Tx, *pgxpool.Conn, error){
+	return w.DatabasePGServer.StartTx(ctx)
+}
+
+// About release the connection of a transaction
+func (w WorkerRepository) ReleaseTx(conn *pgxpool.Conn){
+	w.DatabasePGServer.ReleaseTx(conn)
+}
+
+// About check the database, a exhausted pool fails when the ctx deadline is reached
+func (w WorkerRepository) Ping(ctx context.Context) error{
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	err = conn.Ping(ctx)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
+
+// About the metadata filter as a jsonb document (nil is no filter)
+func metadataFilter(listFilter *model.ListFilter) []byte {
+	if len(listFilter.Metadata) == 0 {
+		return nil
+	}
+	filter, err := json.Marshal(listFilter.Metadata)
+	if err != nil {
+		return nil
+	}
+	return filter
+}
+
 // About add credit
 func (w WorkerRepository) AddCredit(ctx context.Context, tx pgx.Tx, credit *model.AccountStatement) (*model.AccountStatement, error){
 	childLogger.Info().Str("func","AddCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
@@ -47,12 +91,16 @@ func (w WorkerRepository) AddCredit(ctx context.Context, tx pgx.Tx, credit *mode
 											currency,
 											amount,
 											tenant_id,
-											transaction_id) 
-			 VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+											transaction_id,
+											reversal_of,
+											obs,
+											metadata) 
+			 VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
 
-	row := tx.QueryRow(ctx, query, credit.FkAccountID, credit.Type, credit.ChargeAt, credit.Currency, credit.Amount, credit.TenantID, credit.TransactionID)								
+	row := tx.QueryRow(ctx, query, credit.FkAccountID, credit.Type, credit.ChargeAt, credit.Currency, credit.Amount, credit.TenantID, credit.TransactionID, credit.ReversalOf, credit.Obs, credit.Metadata)								
 	var id int
 	if err := row.Scan(&id); err != nil {
+		telemetry.RecordError(span, err)
 		return nil, errors.New(err.Error())
 	}
 
@@ -61,8 +109,8 @@ func (w WorkerRepository) AddCredit(ctx context.Context, tx pgx.Tx, credit *mode
 	return credit , nil
 }
 
-// About list credit
-func (w WorkerRepository) ListCredit(ctx context.Context, credit *model.AccountStatement) (*[]model.AccountStatement, error){
+// About list credit (keyset pagination, ordered by charged_at desc, id desc)
+func (w WorkerRepository) ListCredit(ctx context.Context, credit *model.AccountStatement, listFilter *model.ListFilter) (*[]model.AccountStatement, error){
 	childLogger.Info().Str("func","ListCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 	
 	// Trace
@@ -72,6 +120,7 @@ func (w WorkerRepository) ListCredit(ctx context.Context, credit *model.AccountS
 	// Prepare
 	conn, err := w.DatabasePGServer.Acquire(ctx)
 	if err != nil {
+		telemetry.RecordError(span, err)
 		return nil, errors.New(err.Error())
 	}
 	defer w.DatabasePGServer.Release(conn)
@@ -80,19 +129,45 @@ func (w WorkerRepository) ListCredit(ctx context.Context, credit *model.AccountS
 	res_accountStatement_list := []model.AccountStatement{}
 
 	// Query e Execute
-	query := `SELECT id, 
-					fk_account_id, 
-					type_charge,
-					charged_at,
-					currency, 
-					amount,																										
-					tenant_id,
-					transaction_id	
-				FROM account_statement 
-					WHERE fk_account_id =$1 and type_charge= $2 order by charged_at desc`
-
-	rows, err := conn.Query(ctx, query, credit.FkAccountID, credit.Type)
+	query := `SELECT a.id, 
+					a.fk_account_id, 
+					a.type_charge,
+					a.charged_at,
+					a.currency, 
+					a.amount,																										
+					a.tenant_id,
+					a.transaction_id,
+					coalesce(a.obs, '') as obs,
+					a.metadata,
+					a.reversal_of,
+					(SELECT sum(abs(r.amount)) 
+						FROM account_statement r 
+						WHERE r.reversal_of = a.transaction_id
+						and r.tenant_id = a.tenant_id) as reversed_amount
+				FROM account_statement a
+					WHERE a.fk_account_id =$1 
+					and a.type_charge = any($2)
+					and ($4 = 0 or (a.charged_at, a.id) < ($3, $4))
+					and ($5 = '' or a.currency = $5)
+					and ($6::numeric is null or a.amount >= $6)
+					and ($7::numeric is null or a.amount <= $7)
+					and a.tenant_id = $8
+					and ($10::jsonb is null or a.metadata @> $10::jsonb)
+					order by a.charged_at desc, a.id desc
+					limit $9`
+
+	rows, err := conn.Query(ctx, query,	credit.FkAccountID, 
+										[]string{credit.Type, "CREDIT-REVERSAL"},
+										listFilter.CursorChargeAt,
+										listFilter.CursorID,
+										listFilter.Currency,
+										listFilter.AmountMin,
+										listFilter.AmountMax,
+										credit.TenantID,
+										listFilter.Limit,
+										metadataFilter(listFilter))
 	if err != nil {
+		telemetry.RecordError(span, err)
 		return nil, errors.New(err.Error())
 	}
 	defer rows.Close()
@@ -106,8 +181,13 @@ func (w WorkerRepository) ListCredit(ctx context.Context, credit *model.AccountS
 							&res_accountStatement.Amount,
 							&res_accountStatement.TenantID,
 							&res_accountStatement.TransactionID,
+							&res_accountStatement.Obs,
+							&res_accountStatement.Metadata,
+							&res_accountStatement.ReversalOf,
+							&res_accountStatement.ReversedAmount,
 						)
 		if err != nil {
+			telemetry.RecordError(span, err)
 			return nil, errors.New(err.Error())
         }
 		res_accountStatement_list = append(res_accountStatement_list, res_accountStatement)
@@ -116,8 +196,8 @@ func (w WorkerRepository) ListCredit(ctx context.Context, credit *model.AccountS
 	return &res_accountStatement_list , nil
 }
 
-// About list credit per date
-func (w WorkerRepository) ListCreditPerDate(ctx context.Context, credit *model.AccountStatement) (*[]model.AccountStatement, error){
+// About list credit per date (date_start <= charged_at < date_end)
+func (w WorkerRepository) ListCreditPerDate(ctx context.Context, credit *model.AccountStatement, listFilter *model.ListFilter) (*[]model.AccountStatement, error){
 	childLogger.Info().Str("func","ListCreditPerDate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	// Trace
@@ -127,6 +207,7 @@ func (w WorkerRepository) ListCreditPerDate(ctx context.Context, credit *model.A
 	// Prepare 
 	conn, err := w.DatabasePGServer.Acquire(ctx)
 	if err != nil {
+		telemetry.RecordError(span, err)
 		return nil, errors.New(err.Error())
 	}
 	defer w.DatabasePGServer.Release(conn)
@@ -135,22 +216,33 @@ func (w WorkerRepository) ListCreditPerDate(ctx context.Context, credit *model.A
 	res_accountStatement_list := []model.AccountStatement{}
 
 	// Query e Execute
-	query := `SELECT id, 
-					fk_account_id, 
-					type_charge,
-					charged_at,
-					currency, 
-					amount,																										
-					tenant_id,
-					transaction_id	
-			FROM account_statement 
-			WHERE fk_account_id =$1 
-			and type_charge= $2
-			and charged_at >= $3
-			order by charged_at desc`
-
-	rows, err := conn.Query(ctx, query, credit.FkAccountID, credit.Type, credit.ChargeAt)
+	query := `SELECT a.id, 
+					a.fk_account_id, 
+					a.type_charge,
+					a.charged_at,
+					a.currency, 
+					a.amount,																										
+					a.tenant_id,
+					a.transaction_id,
+					coalesce(a.obs, '') as obs,
+					a.metadata,
+					a.reversal_of,
+					(SELECT sum(abs(r.amount)) 
+						FROM account_statement r 
+						WHERE r.reversal_of = a.transaction_id
+						and r.tenant_id = a.tenant_id) as reversed_amount
+			FROM account_statement a
+			WHERE a.fk_account_id =$1 
+			and a.type_charge = any($2)
+			and a.charged_at >= $3
+			and a.charged_at < $4
+			and ($5::jsonb is null or a.metadata @> $5::jsonb)
+			and a.tenant_id = $6
+			order by a.charged_at desc`
+
+	rows, err := conn.Query(ctx, query, credit.FkAccountID, []string{credit.Type, "CREDIT-REVERSAL"}, listFilter.DateStart, listFilter.DateEnd, metadataFilter(listFilter), credit.TenantID)
 	if err != nil {
+		telemetry.RecordError(span, err)
 		return nil, errors.New(err.Error())
 	}
 	defer rows.Close()
@@ -164,8 +256,13 @@ func (w WorkerRepository) ListCreditPerDate(ctx context.Context, credit *model.A
 							&res_accountStatement.Amount,
 							&res_accountStatement.TenantID,
 							&res_accountStatement.TransactionID,
+							&res_accountStatement.Obs,
+							&res_accountStatement.Metadata,
+							&res_accountStatement.ReversalOf,
+							&res_accountStatement.ReversedAmount,
 						)
 		if err != nil {
+			telemetry.RecordError(span, err)
 			return nil, errors.New(err.Error())
         }
 		res_accountStatement_list = append(res_accountStatement_list, res_accountStatement)
@@ -174,6 +271,151 @@ func (w WorkerRepository) ListCreditPerDate(ctx context.Context, credit *model.A
 	return &res_accountStatement_list , nil
 }
 
+// About get a credit by id or transaction id of a tenant
+func (w WorkerRepository) GetCredit(ctx context.Context, credit *model.AccountStatement) (*model.AccountStatement, error){
+	childLogger.Info().Str("func","GetCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetCredit")
+	defer span.End()
+
+	// Prepare
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	transaction_id := ""
+	if credit.TransactionID != nil {
+		transaction_id = *credit.TransactionID
+	}
+
+	res_accountStatement := model.AccountStatement{}
+
+	// Query e Execute
+	query := `SELECT a.id, 
+					a.fk_account_id, 
+					a.type_charge,
+					a.charged_at,
+					a.currency, 
+					a.amount,																										
+					a.tenant_id,
+					a.transaction_id,
+					coalesce(a.obs, '') as obs,
+					a.metadata,
+					a.reversal_of,
+					(SELECT sum(abs(r.amount)) 
+						FROM account_statement r 
+						WHERE r.reversal_of = a.transaction_id
+						and r.tenant_id = a.tenant_id) as reversed_amount
+			FROM account_statement a
+			WHERE ($1 = 0 or a.id = $1)
+			and ($2 = '' or a.transaction_id = $2)
+			and a.tenant_id = $3`
+
+	row := conn.QueryRow(ctx, query, credit.ID, transaction_id, credit.TenantID)
+	err = row.Scan(	&res_accountStatement.ID, 
+					&res_accountStatement.FkAccountID, 
+					&res_accountStatement.Type, 
+					&res_accountStatement.ChargeAt,
+					&res_accountStatement.Currency,
+					&res_accountStatement.Amount,
+					&res_accountStatement.TenantID,
+					&res_accountStatement.TransactionID,
+					&res_accountStatement.Obs,
+					&res_accountStatement.Metadata,
+					&res_accountStatement.ReversalOf,
+					&res_accountStatement.ReversedAmount,
+				)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, erro.ErrNotFound
+		}
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+
+	return &res_accountStatement, nil
+}
+
+// About get a credit of a tenant by transaction id and lock it for update
+func (w WorkerRepository) GetCreditByTransactionID(ctx context.Context, tx pgx.Tx, credit *model.AccountStatement) (*model.AccountStatement, error){
+	childLogger.Info().Str("func","GetCreditByTransactionID").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetCreditByTransactionID")
+	defer span.End()
+
+	res_accountStatement := model.AccountStatement{}
+
+	// Query e Execute
+	query := `SELECT a.id, 
+					a.fk_account_id, 
+					a.type_charge,
+					a.charged_at,
+					a.currency, 
+					a.amount,																										
+					a.tenant_id,
+					a.transaction_id,
+					coalesce(a.obs, '') as obs,
+					a.metadata,
+					a.reversal_of
+			FROM account_statement a
+			WHERE a.transaction_id = $1
+			and a.type_charge = $2
+			and a.tenant_id = $3
+			FOR UPDATE`
+
+	row := tx.QueryRow(ctx, query, credit.TransactionID, credit.Type, credit.TenantID)
+	err := row.Scan(	&res_accountStatement.ID, 
+						&res_accountStatement.FkAccountID, 
+						&res_accountStatement.Type, 
+						&res_accountStatement.ChargeAt,
+						&res_accountStatement.Currency,
+						&res_accountStatement.Amount,
+						&res_accountStatement.TenantID,
+						&res_accountStatement.TransactionID,
+						&res_accountStatement.Obs,
+						&res_accountStatement.Metadata,
+						&res_accountStatement.ReversalOf,
+					)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, erro.ErrNotFound
+		}
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+
+	return &res_accountStatement, nil
+}
+
+// About get the amount already reversed of a credit
+func (w WorkerRepository) GetReversedAmount(ctx context.Context, tx pgx.Tx, credit *model.AccountStatement) (model.Money, error){
+	childLogger.Info().Str("func","GetReversedAmount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetReversedAmount")
+	defer span.End()
+
+	// Query e Execute (must run after the original credit is locked)
+	query := `SELECT coalesce(sum(abs(amount)),0) 
+			FROM account_statement 
+			WHERE reversal_of = $1
+			and tenant_id = $2`
+
+	var reversed_amount model.Money
+	row := tx.QueryRow(ctx, query, credit.TransactionID, credit.TenantID)
+	if err := row.Scan(&reversed_amount); err != nil {
+		telemetry.RecordError(span, err)
+		return model.Money{}, errors.New(err.Error())
+	}
+
+	return reversed_amount, nil
+}
+
 // About create a uuid transaction
 func (w WorkerRepository) GetTransactionUUID(ctx context.Context) (*string, error){
 	childLogger.Info().Str("func","GetTransactionUUID").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
@@ -184,6 +426,7 @@ func (w WorkerRepository) GetTransactionUUID(ctx context.Context) (*string, erro
 
 	conn, err := w.DatabasePGServer.Acquire(ctx)
 	if err != nil {
+		telemetry.RecordError(span, err)
 		return nil, errors.New(err.Error())
 	}
 	defer w.DatabasePGServer.Release(conn)
@@ -196,6 +439,7 @@ func (w WorkerRepository) GetTransactionUUID(ctx context.Context) (*string, erro
 
 	rows, err := conn.Query(ctx, query)
 	if err != nil {
+		telemetry.RecordError(span, err)
 		return nil, errors.New(err.Error())
 	}
 	defer rows.Close()
@@ -203,6 +447,7 @@ func (w WorkerRepository) GetTransactionUUID(ctx context.Context) (*string, erro
 	for rows.Next() {
 		err := rows.Scan(&uuid) 
 		if err != nil {
+			telemetry.RecordError(span, err)
 			return nil, errors.New(err.Error())
         }
 		return &uuid, nil
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/infra/telemetry"
+)
+
+// About stream the credits of an account of a tenant ordered by charged_at asc, id asc.
+// The rows are read from the cursor one by one and handed to fn, nothing is kept in memory
+func (w WorkerRepository) StreamCredit(ctx context.Context, credit *model.AccountStatement, listFilter *model.ListFilter, fn func(*model.AccountStatement) error) error{
+	childLogger.Info().Str("func","StreamCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.StreamCredit")
+	defer span.End()
+
+	// Prepare
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// a date without value is no filter
+	var date_start, date_end *time.Time
+	if !listFilter.DateStart.IsZero() {
+		date_start = &listFilter.DateStart
+	}
+	if !listFilter.DateEnd.IsZero() {
+		date_end = &listFilter.DateEnd
+	}
+
+	// Query e Execute
+	query := `SELECT a.id,
+					a.fk_account_id,
+					a.type_charge,
+					a.charged_at,
+					a.currency,
+					a.amount,
+					a.tenant_id,
+					a.transaction_id,
+					coalesce(a.obs, '') as obs,
+					a.metadata,
+					a.reversal_of,
+					(SELECT sum(abs(r.amount))
+						FROM account_statement r
+						WHERE r.reversal_of = a.transaction_id
+						and r.tenant_id = a.tenant_id) as reversed_amount
+				FROM account_statement a
+					WHERE a.fk_account_id =$1
+					and a.type_charge = any($2)
+					and ($3::timestamptz is null or a.charged_at >= $3)
+					and ($4::timestamptz is null or a.charged_at < $4)
+					and ($5 = '' or a.currency = $5)
+					and ($6::numeric is null or a.amount >= $6)
+					and ($7::numeric is null or a.amount <= $7)
+					and a.tenant_id = $8
+					and ($9::jsonb is null or a.metadata @> $9::jsonb)
+					order by a.charged_at asc, a.id asc`
+
+	rows, err := conn.Query(ctx, query,	credit.FkAccountID,
+										[]string{credit.Type, "CREDIT-REVERSAL"},
+										date_start,
+										date_end,
+										listFilter.Currency,
+										listFilter.AmountMin,
+										listFilter.AmountMax,
+										credit.TenantID,
+										metadataFilter(listFilter))
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		res_accountStatement := model.AccountStatement{}
+		err := rows.Scan( 	&res_accountStatement.ID,
+							&res_accountStatement.FkAccountID,
+							&res_accountStatement.Type,
+							&res_accountStatement.ChargeAt,
+							&res_accountStatement.Currency,
+							&res_accountStatement.Amount,
+							&res_accountStatement.TenantID,
+							&res_accountStatement.TransactionID,
+							&res_accountStatement.Obs,
+							&res_accountStatement.Metadata,
+							&res_accountStatement.ReversalOf,
+							&res_accountStatement.ReversedAmount,
+						)
+		if err != nil {
+			telemetry.RecordError(span, err)
+			return errors.New(err.Error())
+		}
+
+		err = fn(&res_accountStatement)
+		if err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		telemetry.RecordError(span, err)
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
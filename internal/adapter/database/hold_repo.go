@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/infra/telemetry"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About add a hold
+func (w WorkerRepository) AddHold(ctx context.Context, tx pgx.Tx, hold *model.Hold) (*model.Hold, error){
+	childLogger.Info().Str("func","AddHold").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddHold")
+	defer span.End()
+
+	// Prepare
+	hold.CreatedAt = time.Now()
+	hold.Status = "PENDING"
+
+	// Query e Execute
+	query := `INSERT INTO credit_hold (fk_account_id,
+										currency,
+										amount,
+										tenant_id,
+										transaction_id,
+										status,
+										obs,
+										metadata,
+										created_at,
+										expires_at)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+
+	row := tx.QueryRow(ctx, query,	hold.FkAccountID,
+									hold.Currency,
+									hold.Amount,
+									hold.TenantID,
+									hold.TransactionID,
+									hold.Status,
+									hold.Obs,
+									hold.Metadata,
+									hold.CreatedAt,
+									hold.ExpiresAt)
+	var id int
+	if err := row.Scan(&id); err != nil {
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+
+	hold.ID = id
+
+	return hold, nil
+}
+
+// About get a hold of a tenant by id and lock it for update
+func (w WorkerRepository) GetHold(ctx context.Context, tx pgx.Tx, hold *model.Hold) (*model.Hold, error){
+	childLogger.Info().Str("func","GetHold").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetHold")
+	defer span.End()
+
+	res_hold := model.Hold{}
+
+	// Query e Execute
+	query := `SELECT id,
+					fk_account_id,
+					currency,
+					amount,
+					tenant_id,
+					transaction_id,
+					status,
+					coalesce(obs, '') as obs,
+					metadata,
+					fk_credit_id,
+					created_at,
+					expires_at,
+					updated_at
+			FROM credit_hold
+			WHERE id = $1
+			and tenant_id = $2
+			FOR UPDATE`
+
+	row := tx.QueryRow(ctx, query, hold.ID, hold.TenantID)
+	err := row.Scan(&res_hold.ID,
+					&res_hold.FkAccountID,
+					&res_hold.Currency,
+					&res_hold.Amount,
+					&res_hold.TenantID,
+					&res_hold.TransactionID,
+					&res_hold.Status,
+					&res_hold.Obs,
+					&res_hold.Metadata,
+					&res_hold.FkCreditID,
+					&res_hold.CreatedAt,
+					&res_hold.ExpiresAt,
+					&res_hold.UpdatedAt,
+				)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, erro.ErrNotFound
+		}
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+
+	return &res_hold, nil
+}
+
+// About update the status of a hold
+func (w WorkerRepository) UpdateHold(ctx context.Context, tx pgx.Tx, hold *model.Hold) (int64, error){
+	childLogger.Info().Str("func","UpdateHold").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateHold")
+	defer span.End()
+
+	// Prepare
+	update_at := time.Now()
+	hold.UpdatedAt = &update_at
+
+	// Query e Execute
+	query := `UPDATE credit_hold
+				SET status = $2,
+					fk_credit_id = $3,
+					updated_at = $4
+				WHERE id = $1
+				and tenant_id = $5`
+
+	row, err := tx.Exec(ctx, query,	hold.ID,
+									hold.Status,
+									hold.FkCreditID,
+									hold.UpdatedAt,
+									hold.TenantID)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About expire the pending holds with expires_at before a time
+func (w WorkerRepository) ExpireHold(ctx context.Context, tx pgx.Tx, expiresAt time.Time) (int64, error){
+	childLogger.Debug().Str("func","ExpireHold").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ExpireHold")
+	defer span.End()
+
+	// Query e Execute
+	query := `UPDATE credit_hold
+				SET status = 'EXPIRED',
+					updated_at = $2
+				WHERE status = 'PENDING'
+				and expires_at <= $1`
+
+	row, err := tx.Exec(ctx, query, expiresAt, time.Now())
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
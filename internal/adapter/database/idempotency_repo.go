@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/infra/telemetry"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About get a idempotency key of a tenant
+func (w WorkerRepository) GetIdempotencyKey(ctx context.Context, tenantID string, key string) (*model.IdempotencyKey, error){
+	childLogger.Info().Str("func","GetIdempotencyKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetIdempotencyKey")
+	defer span.End()
+
+	// Prepare
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	res_idempotencyKey := model.IdempotencyKey{}
+
+	// Query e Execute
+	query := `SELECT idempotency_key,
+					tenant_id,
+					request_hash,
+					status_code,
+					response,
+					created_at,
+					expires_at
+				FROM credit_idempotency
+				WHERE tenant_id = $1
+				and idempotency_key = $2
+				and expires_at > $3`
+
+	row := conn.QueryRow(ctx, query, tenantID, key, time.Now())
+	err = row.Scan(	&res_idempotencyKey.Key,
+					&res_idempotencyKey.TenantID,
+					&res_idempotencyKey.RequestHash,
+					&res_idempotencyKey.StatusCode,
+					&res_idempotencyKey.Response,
+					&res_idempotencyKey.CreatedAt,
+					&res_idempotencyKey.ExpiresAt,
+				)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, erro.ErrNotFound
+		}
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+
+	return &res_idempotencyKey, nil
+}
+
+// About reserve a idempotency key (an expired key can be reused)
+func (w WorkerRepository) ReserveIdempotencyKey(ctx context.Context, tx pgx.Tx, idempotencyKey *model.IdempotencyKey) (*model.IdempotencyKey, error){
+	childLogger.Info().Str("func","ReserveIdempotencyKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ReserveIdempotencyKey")
+	defer span.End()
+
+	// Prepare
+	idempotencyKey.CreatedAt = time.Now()
+
+	// Query e Execute
+	query := `INSERT INTO credit_idempotency (idempotency_key,
+											tenant_id,
+											request_hash,
+											status_code,
+											created_at,
+											expires_at)
+			VALUES($1, $2, $3, 0, $4, $5)
+			ON CONFLICT (tenant_id, idempotency_key) DO UPDATE
+				SET request_hash = EXCLUDED.request_hash,
+					status_code = 0,
+					response = null,
+					created_at = EXCLUDED.created_at,
+					expires_at = EXCLUDED.expires_at
+				WHERE credit_idempotency.expires_at <= EXCLUDED.created_at
+			RETURNING idempotency_key`
+
+	row := tx.QueryRow(ctx, query,	idempotencyKey.Key,
+									idempotencyKey.TenantID,
+									idempotencyKey.RequestHash,
+									idempotencyKey.CreatedAt,
+									idempotencyKey.ExpiresAt)
+	var key string
+	if err := row.Scan(&key); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			telemetry.RecordError(span, erro.ErrIdempotencyConflict)
+			return nil, erro.ErrIdempotencyConflict
+		}
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+
+	return idempotencyKey, nil
+}
+
+// About store the response of a idempotency key
+func (w WorkerRepository) UpdateIdempotencyKey(ctx context.Context, tx pgx.Tx, idempotencyKey *model.IdempotencyKey) (int64, error){
+	childLogger.Info().Str("func","UpdateIdempotencyKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateIdempotencyKey")
+	defer span.End()
+
+	// Query e Execute
+	query := `UPDATE credit_idempotency
+				SET status_code = $2,
+					response = $3
+				WHERE tenant_id = $4
+				and idempotency_key = $1`
+
+	row, err := tx.Exec(ctx, query,	idempotencyKey.Key,
+									idempotencyKey.StatusCode,
+									idempotencyKey.Response,
+									idempotencyKey.TenantID)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About release a idempotency key still reserved (without response)
+func (w WorkerRepository) DeleteIdempotencyKey(ctx context.Context, tx pgx.Tx, idempotencyKey *model.IdempotencyKey) (int64, error){
+	childLogger.Info().Str("func","DeleteIdempotencyKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.DeleteIdempotencyKey")
+	defer span.End()
+
+	// Query e Execute
+	query := `DELETE FROM credit_idempotency
+				WHERE tenant_id = $2
+				and idempotency_key = $1
+				and status_code = 0`
+
+	row, err := tx.Exec(ctx, query,	idempotencyKey.Key,
+									idempotencyKey.TenantID)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/infra/telemetry"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About add a outbox event
+func (w WorkerRepository) AddOutboxEvent(ctx context.Context, tx pgx.Tx, outboxEvent *model.OutboxEvent) (*model.OutboxEvent, error){
+	childLogger.Info().Str("func","AddOutboxEvent").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.AddOutboxEvent")
+	defer span.End()
+
+	// Prepare
+	outboxEvent.CreatedAt = time.Now()
+	outboxEvent.NextAttemptAt = outboxEvent.CreatedAt
+	outboxEvent.Status = "PENDING"
+
+	// Query e Execute
+	query := `INSERT INTO credit_outbox (aggregate_id,
+										event_type,
+										payload,
+										status,
+										attempts,
+										next_attempt_at,
+										created_at)
+			VALUES($1, $2, $3, $4, 0, $5, $6) RETURNING id`
+
+	row := tx.QueryRow(ctx, query,	outboxEvent.AggregateID,
+									outboxEvent.EventType,
+									outboxEvent.Payload,
+									outboxEvent.Status,
+									outboxEvent.NextAttemptAt,
+									outboxEvent.CreatedAt)
+	var id int
+	if err := row.Scan(&id); err != nil {
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+
+	outboxEvent.ID = id
+
+	return outboxEvent, nil
+}
+
+// About lock the pending outbox events ready to be delivered
+func (w WorkerRepository) ListPendingOutboxEvent(ctx context.Context, tx pgx.Tx, limit int) (*[]model.OutboxEvent, error){
+	childLogger.Debug().Str("func","ListPendingOutboxEvent").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListPendingOutboxEvent")
+	defer span.End()
+
+	res_outboxEvent := model.OutboxEvent{}
+	res_outboxEvent_list := []model.OutboxEvent{}
+
+	// Query e Execute
+	query := `SELECT id,
+					aggregate_id,
+					event_type,
+					payload,
+					status,
+					attempts,
+					next_attempt_at,
+					last_error,
+					created_at,
+					updated_at
+				FROM credit_outbox
+				WHERE status = 'PENDING'
+				and next_attempt_at <= $1
+				order by id
+				limit $2
+				FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.Query(ctx, query, time.Now(), limit)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err := rows.Scan(	&res_outboxEvent.ID,
+							&res_outboxEvent.AggregateID,
+							&res_outboxEvent.EventType,
+							&res_outboxEvent.Payload,
+							&res_outboxEvent.Status,
+							&res_outboxEvent.Attempts,
+							&res_outboxEvent.NextAttemptAt,
+							&res_outboxEvent.LastError,
+							&res_outboxEvent.CreatedAt,
+							&res_outboxEvent.UpdatedAt,
+						)
+		if err != nil {
+			telemetry.RecordError(span, err)
+			return nil, errors.New(err.Error())
+		}
+		res_outboxEvent_list = append(res_outboxEvent_list, res_outboxEvent)
+	}
+
+	return &res_outboxEvent_list, nil
+}
+
+// About claim a locked outbox event, the next attempt is moved to the end of the claim
+func (w WorkerRepository) ClaimOutboxEvent(ctx context.Context, tx pgx.Tx, outboxEvent *model.OutboxEvent) (int64, error){
+	childLogger.Debug().Str("func","ClaimOutboxEvent").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ClaimOutboxEvent")
+	defer span.End()
+
+	// Prepare
+	update_at := time.Now()
+	outboxEvent.UpdatedAt = &update_at
+
+	// Query e Execute
+	query := `UPDATE credit_outbox
+				SET next_attempt_at = $2,
+					claim_token = $3,
+					updated_at = $4
+				WHERE id = $1`
+
+	row, err := tx.Exec(ctx, query,	outboxEvent.ID,
+									outboxEvent.NextAttemptAt,
+									outboxEvent.ClaimToken,
+									outboxEvent.UpdatedAt)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About update the delivery status of a outbox event, only while the claim of the relay is the current one
+// (no row is updated when the claim expired and another relay claimed the event)
+func (w WorkerRepository) UpdateOutboxEvent(ctx context.Context, tx pgx.Tx, outboxEvent *model.OutboxEvent) (int64, error){
+	childLogger.Debug().Str("func","UpdateOutboxEvent").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.UpdateOutboxEvent")
+	defer span.End()
+
+	// Prepare
+	update_at := time.Now()
+	outboxEvent.UpdatedAt = &update_at
+
+	// Query e Execute
+	query := `UPDATE credit_outbox
+				SET status = $2,
+					attempts = $3,
+					next_attempt_at = $4,
+					last_error = $5,
+					updated_at = $6,
+					claim_token = null
+				WHERE id = $1
+				and claim_token = $7`
+
+	row, err := tx.Exec(ctx, query,	outboxEvent.ID,
+									outboxEvent.Status,
+									outboxEvent.Attempts,
+									outboxEvent.NextAttemptAt,
+									outboxEvent.LastError,
+									outboxEvent.UpdatedAt,
+									outboxEvent.ClaimToken)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+
+	go_core_pg "github.com/eliezerraj/go-core/database/pg"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// About register the pgxpool stats as observable metrics, read at each collect
+func registerPoolMetrics(databasePGServer *go_core_pg.DatabasePGServer) {
+	meter := otel.Meter("go-credit")
+
+	connections, err := meter.Int64ObservableGauge("db_pool_connections",
+									metric.WithDescription("connections of the pool per state (acquired, idle, constructing)"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create db_pool_connections metric")
+		return
+	}
+	maxConnections, err := meter.Int64ObservableGauge("db_pool_max_connections",
+									metric.WithDescription("max size of the pool"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create db_pool_max_connections metric")
+		return
+	}
+	acquireCount, err := meter.Int64ObservableCounter("db_pool_acquire",
+									metric.WithDescription("successful acquires of a connection"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create db_pool_acquire metric")
+		return
+	}
+	emptyAcquireCount, err := meter.Int64ObservableCounter("db_pool_empty_acquire",
+									metric.WithDescription("acquires that waited for a connection (pool empty)"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create db_pool_empty_acquire metric")
+		return
+	}
+	canceledAcquireCount, err := meter.Int64ObservableCounter("db_pool_canceled_acquire",
+									metric.WithDescription("acquires canceled by the context"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create db_pool_canceled_acquire metric")
+		return
+	}
+	acquireDuration, err := meter.Float64ObservableCounter("db_pool_acquire_duration",
+									metric.WithDescription("total time waiting for a connection"),
+									metric.WithUnit("s"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create db_pool_acquire_duration metric")
+		return
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		pool := databasePGServer.GetConnection()
+		if pool == nil {
+			return nil
+		}
+		stat := pool.Stat()
+
+		observer.ObserveInt64(connections, int64(stat.AcquiredConns()), metric.WithAttributes(attribute.String("state", "acquired")))
+		observer.ObserveInt64(connections, int64(stat.IdleConns()), metric.WithAttributes(attribute.String("state", "idle")))
+		observer.ObserveInt64(connections, int64(stat.ConstructingConns()), metric.WithAttributes(attribute.String("state", "constructing")))
+		observer.ObserveInt64(maxConnections, int64(stat.MaxConns()))
+		observer.ObserveInt64(acquireCount, stat.AcquireCount())
+		observer.ObserveInt64(emptyAcquireCount, stat.EmptyAcquireCount())
+		observer.ObserveInt64(canceledAcquireCount, stat.CanceledAcquireCount())
+		observer.ObserveFloat64(acquireDuration, stat.AcquireDuration().Seconds())
+		return nil
+	}, connections, maxConnections, acquireCount, emptyAcquireCount, canceledAcquireCount, acquireDuration)
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error register db pool metrics")
+	}
+}
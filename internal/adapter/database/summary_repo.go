@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/infra/telemetry"
+)
+
+// About the totals of the credits of an account of a tenant per currency (and per day, week or month).
+// The aggregates are computed by the database, the avg is rounded to the scale of the amounts
+func (w WorkerRepository) SummaryCredit(ctx context.Context, credit *model.AccountStatement, summaryFilter *model.SummaryFilter) (*[]model.CreditSummaryGroup, error){
+	childLogger.Info().Str("func","SummaryCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.SummaryCredit")
+	defer span.End()
+
+	// Prepare
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// a date without value is no filter
+	var date_start, date_end *time.Time
+	if !summaryFilter.DateStart.IsZero() {
+		date_start = &summaryFilter.DateStart
+	}
+	if !summaryFilter.DateEnd.IsZero() {
+		date_end = &summaryFilter.DateEnd
+	}
+	// date_trunc of a null field is null, the group by currency has no period
+	var period *string
+	if summaryFilter.GroupBy != "currency" {
+		period = &summaryFilter.GroupBy
+	}
+	time_zone := time.UTC.String()
+	if summaryFilter.Location != nil {
+		time_zone = summaryFilter.Location.String()
+	}
+
+	res_summary_list := []model.CreditSummaryGroup{}
+
+	// Query e Execute
+	query := `SELECT date_trunc($5::text, a.charged_at, $6::text) as period,
+					a.currency,
+					count(*) filter (where a.type_charge = $2) as count,
+					coalesce(sum(a.amount) filter (where a.type_charge = $2), 0) as amount,
+					min(a.amount) filter (where a.type_charge = $2) as min_amount,
+					max(a.amount) filter (where a.type_charge = $2) as max_amount,
+					round(avg(a.amount) filter (where a.type_charge = $2), max(scale(a.amount))) as avg_amount,
+					count(*) filter (where a.type_charge = 'CREDIT-REVERSAL') as reversal_count,
+					coalesce(sum(abs(a.amount)) filter (where a.type_charge = 'CREDIT-REVERSAL'), 0) as reversed_amount,
+					sum(a.amount) as net_amount
+				FROM account_statement a
+					WHERE a.fk_account_id =$1
+					and a.type_charge in ($2, 'CREDIT-REVERSAL')
+					and ($3::timestamptz is null or a.charged_at >= $3)
+					and ($4::timestamptz is null or a.charged_at < $4)
+					and a.tenant_id = $7
+					GROUP BY 1, 2
+					ORDER BY 1 nulls first, 2`
+
+	rows, err := conn.Query(ctx, query,	credit.FkAccountID,
+										credit.Type,
+										date_start,
+										date_end,
+										period,
+										time_zone,
+										credit.TenantID)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		res_summary := model.CreditSummaryGroup{}
+		err := rows.Scan(	&res_summary.Period,
+							&res_summary.Currency,
+							&res_summary.Count,
+							&res_summary.Amount,
+							&res_summary.MinAmount,
+							&res_summary.MaxAmount,
+							&res_summary.AvgAmount,
+							&res_summary.ReversalCount,
+							&res_summary.ReversedAmount,
+							&res_summary.NetAmount,
+						)
+		if err != nil {
+			telemetry.RecordError(span, err)
+			return nil, errors.New(err.Error())
+		}
+		res_summary_list = append(res_summary_list, res_summary)
+	}
+	if err := rows.Err(); err != nil {
+		telemetry.RecordError(span, err)
+		return nil, errors.New(err.Error())
+	}
+
+	return &res_summary_list, nil
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/go-credit/internal/core/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// About add credits, the transaction_id is created by the repository
+func (w *WorkerRepository) AddCreditBatch(ctx context.Context, tx pgx.Tx, credits []model.AccountStatement) ([]model.AccountStatement, error){
+	childLogger.Info().Str("func","AddCreditBatch").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("credits", len(credits)).Send()
+
+	for i := range credits {
+		transaction_id := uuid.NewString()
+		credits[i].TransactionID = &transaction_id
+		if _, err := w.AddCredit(ctx, tx, &credits[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return credits, nil
+}
+
+// About add outbox events
+func (w *WorkerRepository) AddOutboxEventBatch(ctx context.Context, tx pgx.Tx, outboxEvents []model.OutboxEvent) ([]model.OutboxEvent, error){
+	childLogger.Info().Str("func","AddOutboxEventBatch").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("events", len(outboxEvents)).Send()
+
+	for i := range outboxEvents {
+		if _, err := w.AddOutboxEvent(ctx, tx, &outboxEvents[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return outboxEvents, nil
+}
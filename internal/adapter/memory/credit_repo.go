@@ -0,0 +1,376 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/core/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+var childLogger = log.With().Str("component","go-credit").Str("package","internal.adapter.memory").Logger()
+
+var _ service.CreditRepository = (*WorkerRepository)(nil)
+
+var ErrTxInvalid = errors.New("transaction is not a memory transaction")
+
+// the tables, a transaction works on its own copy
+type memoryState struct {
+	credits			[]model.AccountStatement
+	idempotencyKeys	map[string]model.IdempotencyKey
+	outboxEvents	[]model.OutboxEvent
+	holds			[]model.Hold
+	creditSeq		int
+	outboxSeq		int
+	holdSeq			int
+}
+
+// About copy the tables (the rows are values, a row is replaced and never changed in place)
+func (m *memoryState) clone() *memoryState {
+	state := &memoryState{
+		credits: append([]model.AccountStatement{}, m.credits...),
+		idempotencyKeys: make(map[string]model.IdempotencyKey, len(m.idempotencyKeys)),
+		outboxEvents: append([]model.OutboxEvent{}, m.outboxEvents...),
+		holds: append([]model.Hold{}, m.holds...),
+		creditSeq: m.creditSeq,
+		outboxSeq: m.outboxSeq,
+		holdSeq: m.holdSeq,
+	}
+	for key, value := range m.idempotencyKeys {
+		state.idempotencyKeys[key] = value
+	}
+	return state
+}
+
+// in-memory repository, goroutine-safe, the transactions are serialized
+type WorkerRepository struct {
+	mutex	sync.RWMutex
+	state	*memoryState
+	txLock	chan struct{}
+}
+
+// a transaction, only Commit and Rollback are supported
+type memoryTx struct {
+	pgx.Tx
+	repository	*WorkerRepository
+	state		*memoryState
+	closed		bool
+}
+
+func NewWorkerRepository() *WorkerRepository{
+	childLogger.Info().Str("func","NewWorkerRepository").Send()
+
+	return &WorkerRepository{
+		state: &memoryState{ idempotencyKeys: make(map[string]model.IdempotencyKey) },
+		txLock: make(chan struct{}, 1),
+	}
+}
+
+// About start a transaction, it waits the running transaction
+func (w *WorkerRepository) StartTx(ctx context.Context) (pgx.Tx, *pgxpool.Conn, error){
+	select {
+	case w.txLock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+
+	w.mutex.RLock()
+	state := w.state.clone()
+	w.mutex.RUnlock()
+
+	return &memoryTx{repository: w, state: state}, nil, nil
+}
+
+// About release the connection of a transaction (there is none)
+func (w *WorkerRepository) ReleaseTx(conn *pgxpool.Conn){
+}
+
+// About check the repository (always available)
+func (w *WorkerRepository) Ping(ctx context.Context) error{
+	return ctx.Err()
+}
+
+// About publish the transaction copy
+func (t *memoryTx) Commit(ctx context.Context) error {
+	if t.closed {
+		return pgx.ErrTxClosed
+	}
+	t.closed = true
+
+	t.repository.mutex.Lock()
+	t.repository.state = t.state
+	t.repository.mutex.Unlock()
+
+	<-t.repository.txLock
+	return nil
+}
+
+// About discard the transaction copy
+func (t *memoryTx) Rollback(ctx context.Context) error {
+	if t.closed {
+		return pgx.ErrTxClosed
+	}
+	t.closed = true
+
+	<-t.repository.txLock
+	return nil
+}
+
+// About the tables of a open transaction
+func (w *WorkerRepository) txState(tx pgx.Tx) (*memoryState, error) {
+	memTx, ok := tx.(*memoryTx)
+	if !ok || memTx.repository != w {
+		return nil, ErrTxInvalid
+	}
+	if memTx.closed {
+		return nil, pgx.ErrTxClosed
+	}
+	return memTx.state, nil
+}
+
+// About the committed tables
+func (w *WorkerRepository) readState() *memoryState {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.state
+}
+
+// About the sum of the reversals of a credit (nil without reversal)
+func reversedAmount(state *memoryState, credit *model.AccountStatement) (*model.Money, error) {
+	if credit.TransactionID == nil {
+		return nil, nil
+	}
+	var sum *model.Money
+	for _, row := range state.credits {
+		if row.ReversalOf == nil || *row.ReversalOf != *credit.TransactionID || row.TenantID != credit.TenantID {
+			continue
+		}
+		if sum == nil {
+			sum = &model.Money{}
+		}
+		total, err := sum.Add(row.Amount.Abs())
+		if err != nil {
+			return nil, err
+		}
+		sum = &total
+	}
+	return sum, nil
+}
+
+// About the metadata filter (metadata @> filter)
+func matchMetadata(metadata map[string]interface{}, filter map[string]string) bool {
+	for key, value := range filter {
+		field, ok := metadata[key].(string)
+		if !ok || field != value {
+			return false
+		}
+	}
+	return true
+}
+
+// About the credit and reversal types of an account of a tenant
+func matchType(credit *model.AccountStatement, row *model.AccountStatement) bool {
+	return row.FkAccountID == credit.FkAccountID && row.TenantID == credit.TenantID && (row.Type == credit.Type || row.Type == "CREDIT-REVERSAL")
+}
+
+// About sort by charged_at desc, id desc
+func sortCredit(list []model.AccountStatement) {
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].ChargeAt.Equal(list[j].ChargeAt) {
+			return list[i].ChargeAt.After(list[j].ChargeAt)
+		}
+		return list[i].ID > list[j].ID
+	})
+}
+
+// About add credit
+func (w *WorkerRepository) AddCredit(ctx context.Context, tx pgx.Tx, credit *model.AccountStatement) (*model.AccountStatement, error){
+	childLogger.Info().Str("func","AddCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare
+	credit.ChargeAt = time.Now()
+	state.creditSeq = state.creditSeq + 1
+	credit.ID = state.creditSeq
+
+	row := *credit
+	row.AccountID = ""
+	row.RequestID = nil
+	row.ReversedAmount = nil
+	state.credits = append(state.credits, row)
+
+	return credit, nil
+}
+
+// About list credit (keyset pagination, ordered by charged_at desc, id desc)
+func (w *WorkerRepository) ListCredit(ctx context.Context, credit *model.AccountStatement, listFilter *model.ListFilter) (*[]model.AccountStatement, error){
+	childLogger.Info().Str("func","ListCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state := w.readState()
+
+	res_accountStatement_list := []model.AccountStatement{}
+	for _, row := range state.credits {
+		if !matchType(credit, &row) {
+			continue
+		}
+		if listFilter.CursorID != 0 {
+			if row.ChargeAt.After(listFilter.CursorChargeAt) {
+				continue
+			}
+			if row.ChargeAt.Equal(listFilter.CursorChargeAt) && row.ID >= listFilter.CursorID {
+				continue
+			}
+		}
+		if listFilter.Currency != "" && row.Currency != listFilter.Currency {
+			continue
+		}
+		if listFilter.AmountMin != nil && row.Amount.Cmp(*listFilter.AmountMin) < 0 {
+			continue
+		}
+		if listFilter.AmountMax != nil && row.Amount.Cmp(*listFilter.AmountMax) > 0 {
+			continue
+		}
+		if !matchMetadata(row.Metadata, listFilter.Metadata) {
+			continue
+		}
+
+		reversed_amount, err := reversedAmount(state, &row)
+		if err != nil {
+			return nil, err
+		}
+		row.ReversedAmount = reversed_amount
+		res_accountStatement_list = append(res_accountStatement_list, row)
+	}
+
+	sortCredit(res_accountStatement_list)
+	if listFilter.Limit > 0 && len(res_accountStatement_list) > listFilter.Limit {
+		res_accountStatement_list = res_accountStatement_list[:listFilter.Limit]
+	}
+
+	return &res_accountStatement_list, nil
+}
+
+// About list credit per date (date_start <= charged_at < date_end)
+func (w *WorkerRepository) ListCreditPerDate(ctx context.Context, credit *model.AccountStatement, listFilter *model.ListFilter) (*[]model.AccountStatement, error){
+	childLogger.Info().Str("func","ListCreditPerDate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state := w.readState()
+
+	res_accountStatement_list := []model.AccountStatement{}
+	for _, row := range state.credits {
+		if !matchType(credit, &row) {
+			continue
+		}
+		if row.ChargeAt.Before(listFilter.DateStart) || !row.ChargeAt.Before(listFilter.DateEnd) {
+			continue
+		}
+		if !matchMetadata(row.Metadata, listFilter.Metadata) {
+			continue
+		}
+
+		reversed_amount, err := reversedAmount(state, &row)
+		if err != nil {
+			return nil, err
+		}
+		row.ReversedAmount = reversed_amount
+		res_accountStatement_list = append(res_accountStatement_list, row)
+	}
+
+	sortCredit(res_accountStatement_list)
+
+	return &res_accountStatement_list, nil
+}
+
+// About get a credit by id or transaction id of a tenant
+func (w *WorkerRepository) GetCredit(ctx context.Context, credit *model.AccountStatement) (*model.AccountStatement, error){
+	childLogger.Info().Str("func","GetCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state := w.readState()
+
+	for _, row := range state.credits {
+		if credit.ID != 0 && row.ID != credit.ID {
+			continue
+		}
+		if credit.TransactionID != nil && *credit.TransactionID != "" {
+			if row.TransactionID == nil || *row.TransactionID != *credit.TransactionID {
+				continue
+			}
+		}
+		if row.TenantID != credit.TenantID {
+			continue
+		}
+
+		reversed_amount, err := reversedAmount(state, &row)
+		if err != nil {
+			return nil, err
+		}
+		row.ReversedAmount = reversed_amount
+		return &row, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About get a credit of a tenant by transaction id (the transaction is already exclusive)
+func (w *WorkerRepository) GetCreditByTransactionID(ctx context.Context, tx pgx.Tx, credit *model.AccountStatement) (*model.AccountStatement, error){
+	childLogger.Info().Str("func","GetCreditByTransactionID").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range state.credits {
+		if row.TransactionID == nil || credit.TransactionID == nil || *row.TransactionID != *credit.TransactionID {
+			continue
+		}
+		if row.Type != credit.Type || row.TenantID != credit.TenantID {
+			continue
+		}
+		return &row, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About get the amount already reversed of a credit of a tenant
+func (w *WorkerRepository) GetReversedAmount(ctx context.Context, tx pgx.Tx, credit *model.AccountStatement) (model.Money, error){
+	childLogger.Info().Str("func","GetReversedAmount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return model.Money{}, err
+	}
+
+	reversed_amount, err := reversedAmount(state, credit)
+	if err != nil {
+		return model.Money{}, err
+	}
+	if reversed_amount == nil {
+		return model.Money{}, nil
+	}
+
+	return *reversed_amount, nil
+}
+
+// About create a uuid transaction
+func (w *WorkerRepository) GetTransactionUUID(ctx context.Context) (*string, error){
+	childLogger.Info().Str("func","GetTransactionUUID").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	uuid := uuid.NewString()
+
+	return &uuid, nil
+}
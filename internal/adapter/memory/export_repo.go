@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-credit/internal/core/model"
+)
+
+// About stream the credits of an account of a tenant ordered by charged_at asc, id asc
+func (w *WorkerRepository) StreamCredit(ctx context.Context, credit *model.AccountStatement, listFilter *model.ListFilter, fn func(*model.AccountStatement) error) error{
+	childLogger.Info().Str("func","StreamCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state := w.readState()
+
+	res_accountStatement_list := []model.AccountStatement{}
+	for _, row := range state.credits {
+		if !matchType(credit, &row) {
+			continue
+		}
+		if !listFilter.DateStart.IsZero() && row.ChargeAt.Before(listFilter.DateStart) {
+			continue
+		}
+		if !listFilter.DateEnd.IsZero() && !row.ChargeAt.Before(listFilter.DateEnd) {
+			continue
+		}
+		if listFilter.Currency != "" && row.Currency != listFilter.Currency {
+			continue
+		}
+		if listFilter.AmountMin != nil && row.Amount.Cmp(*listFilter.AmountMin) < 0 {
+			continue
+		}
+		if listFilter.AmountMax != nil && row.Amount.Cmp(*listFilter.AmountMax) > 0 {
+			continue
+		}
+		if !matchMetadata(row.Metadata, listFilter.Metadata) {
+			continue
+		}
+		res_accountStatement_list = append(res_accountStatement_list, row)
+	}
+
+	sort.Slice(res_accountStatement_list, func(i, j int) bool {
+		if !res_accountStatement_list[i].ChargeAt.Equal(res_accountStatement_list[j].ChargeAt) {
+			return res_accountStatement_list[i].ChargeAt.Before(res_accountStatement_list[j].ChargeAt)
+		}
+		return res_accountStatement_list[i].ID < res_accountStatement_list[j].ID
+	})
+
+	for _, row := range res_accountStatement_list {
+		reversed_amount, err := reversedAmount(state, &row)
+		if err != nil {
+			return err
+		}
+		row.ReversedAmount = reversed_amount
+
+		err = fn(&row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
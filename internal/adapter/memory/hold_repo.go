@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About add a hold
+func (w *WorkerRepository) AddHold(ctx context.Context, tx pgx.Tx, hold *model.Hold) (*model.Hold, error){
+	childLogger.Info().Str("func","AddHold").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare
+	hold.CreatedAt = time.Now()
+	hold.Status = "PENDING"
+	state.holdSeq = state.holdSeq + 1
+	hold.ID = state.holdSeq
+
+	row := *hold
+	row.AccountID = ""
+	row.Credit = nil
+	state.holds = append(state.holds, row)
+
+	return hold, nil
+}
+
+// About get a hold of a tenant by id (the transaction is already exclusive)
+func (w *WorkerRepository) GetHold(ctx context.Context, tx pgx.Tx, hold *model.Hold) (*model.Hold, error){
+	childLogger.Info().Str("func","GetHold").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range state.holds {
+		if row.ID == hold.ID && row.TenantID == hold.TenantID {
+			return &row, nil
+		}
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About update the status of a hold
+func (w *WorkerRepository) UpdateHold(ctx context.Context, tx pgx.Tx, hold *model.Hold) (int64, error){
+	childLogger.Info().Str("func","UpdateHold").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Prepare
+	update_at := time.Now()
+	hold.UpdatedAt = &update_at
+
+	for i := range state.holds {
+		if state.holds[i].ID == hold.ID && state.holds[i].TenantID == hold.TenantID {
+			state.holds[i].Status = hold.Status
+			state.holds[i].FkCreditID = hold.FkCreditID
+			state.holds[i].UpdatedAt = hold.UpdatedAt
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// About expire the pending holds with expires_at before a time
+func (w *WorkerRepository) ExpireHold(ctx context.Context, tx pgx.Tx, expiresAt time.Time) (int64, error){
+	childLogger.Debug().Str("func","ExpireHold").Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	update_at := time.Now()
+	var count int64
+	for i := range state.holds {
+		if state.holds[i].Status == "PENDING" && !state.holds[i].ExpiresAt.After(expiresAt) {
+			state.holds[i].Status = "EXPIRED"
+			state.holds[i].UpdatedAt = &update_at
+			count = count + 1
+		}
+	}
+
+	return count, nil
+}
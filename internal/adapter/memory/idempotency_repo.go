@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About the keys are unique per tenant
+func idempotencyMapKey(tenantID string, key string) string {
+	return tenantID + "/" + key
+}
+
+// About get a idempotency key of a tenant
+func (w *WorkerRepository) GetIdempotencyKey(ctx context.Context, tenantID string, key string) (*model.IdempotencyKey, error){
+	childLogger.Info().Str("func","GetIdempotencyKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state := w.readState()
+
+	res_idempotencyKey, ok := state.idempotencyKeys[idempotencyMapKey(tenantID, key)]
+	if !ok || !res_idempotencyKey.ExpiresAt.After(time.Now()) {
+		return nil, erro.ErrNotFound
+	}
+
+	return &res_idempotencyKey, nil
+}
+
+// About reserve a idempotency key (an expired key can be reused)
+func (w *WorkerRepository) ReserveIdempotencyKey(ctx context.Context, tx pgx.Tx, idempotencyKey *model.IdempotencyKey) (*model.IdempotencyKey, error){
+	childLogger.Info().Str("func","ReserveIdempotencyKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare
+	idempotencyKey.CreatedAt = time.Now()
+
+	current, ok := state.idempotencyKeys[idempotencyMapKey(idempotencyKey.TenantID, idempotencyKey.Key)]
+	if ok && current.ExpiresAt.After(idempotencyKey.CreatedAt) {
+		return nil, erro.ErrIdempotencyConflict
+	}
+
+	row := *idempotencyKey
+	row.StatusCode = 0
+	row.Response = nil
+	state.idempotencyKeys[idempotencyMapKey(row.TenantID, row.Key)] = row
+
+	return idempotencyKey, nil
+}
+
+// About store the response of a idempotency key
+func (w *WorkerRepository) UpdateIdempotencyKey(ctx context.Context, tx pgx.Tx, idempotencyKey *model.IdempotencyKey) (int64, error){
+	childLogger.Info().Str("func","UpdateIdempotencyKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	row, ok := state.idempotencyKeys[idempotencyMapKey(idempotencyKey.TenantID, idempotencyKey.Key)]
+	if !ok {
+		return 0, nil
+	}
+	row.StatusCode = idempotencyKey.StatusCode
+	row.Response = append([]byte{}, idempotencyKey.Response...)
+	state.idempotencyKeys[idempotencyMapKey(row.TenantID, row.Key)] = row
+
+	return 1, nil
+}
+
+// About release a idempotency key still reserved (without response)
+func (w *WorkerRepository) DeleteIdempotencyKey(ctx context.Context, tx pgx.Tx, idempotencyKey *model.IdempotencyKey) (int64, error){
+	childLogger.Info().Str("func","DeleteIdempotencyKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	row, ok := state.idempotencyKeys[idempotencyMapKey(idempotencyKey.TenantID, idempotencyKey.Key)]
+	if !ok || row.StatusCode != 0 {
+		return 0, nil
+	}
+	delete(state.idempotencyKeys, idempotencyMapKey(row.TenantID, row.Key))
+
+	return 1, nil
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-credit/internal/core/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About add a outbox event
+func (w *WorkerRepository) AddOutboxEvent(ctx context.Context, tx pgx.Tx, outboxEvent *model.OutboxEvent) (*model.OutboxEvent, error){
+	childLogger.Info().Str("func","AddOutboxEvent").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare
+	outboxEvent.CreatedAt = time.Now()
+	outboxEvent.NextAttemptAt = outboxEvent.CreatedAt
+	outboxEvent.Status = "PENDING"
+	outboxEvent.Attempts = 0
+	state.outboxSeq = state.outboxSeq + 1
+	outboxEvent.ID = state.outboxSeq
+
+	state.outboxEvents = append(state.outboxEvents, *outboxEvent)
+
+	return outboxEvent, nil
+}
+
+// About list the pending outbox events ready to be delivered (the transaction is already exclusive)
+func (w *WorkerRepository) ListPendingOutboxEvent(ctx context.Context, tx pgx.Tx, limit int) (*[]model.OutboxEvent, error){
+	childLogger.Debug().Str("func","ListPendingOutboxEvent").Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res_outboxEvent_list := []model.OutboxEvent{}
+	for _, row := range state.outboxEvents {
+		if len(res_outboxEvent_list) >= limit {
+			break
+		}
+		if row.Status != "PENDING" || row.NextAttemptAt.After(now) {
+			continue
+		}
+		res_outboxEvent_list = append(res_outboxEvent_list, row)
+	}
+
+	return &res_outboxEvent_list, nil
+}
+
+// About claim a outbox event, the next attempt is moved to the end of the claim
+func (w *WorkerRepository) ClaimOutboxEvent(ctx context.Context, tx pgx.Tx, outboxEvent *model.OutboxEvent) (int64, error){
+	childLogger.Debug().Str("func","ClaimOutboxEvent").Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Prepare
+	update_at := time.Now()
+	outboxEvent.UpdatedAt = &update_at
+
+	for i := range state.outboxEvents {
+		if state.outboxEvents[i].ID == outboxEvent.ID {
+			state.outboxEvents[i].NextAttemptAt = outboxEvent.NextAttemptAt
+			state.outboxEvents[i].ClaimToken = outboxEvent.ClaimToken
+			state.outboxEvents[i].UpdatedAt = outboxEvent.UpdatedAt
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// About update the delivery status of a outbox event, only while the claim of the relay is the current one
+func (w *WorkerRepository) UpdateOutboxEvent(ctx context.Context, tx pgx.Tx, outboxEvent *model.OutboxEvent) (int64, error){
+	childLogger.Debug().Str("func","UpdateOutboxEvent").Send()
+
+	state, err := w.txState(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Prepare
+	update_at := time.Now()
+	outboxEvent.UpdatedAt = &update_at
+
+	for i := range state.outboxEvents {
+		row := &state.outboxEvents[i]
+		if row.ID != outboxEvent.ID {
+			continue
+		}
+		if row.ClaimToken == nil || outboxEvent.ClaimToken == nil || *row.ClaimToken != *outboxEvent.ClaimToken {
+			return 0, nil
+		}
+		*row = *outboxEvent
+		row.ClaimToken = nil
+		return 1, nil
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/go-credit/internal/core/model"
+)
+
+// About the start of the period of a charged_at (as the postgres date_trunc, the week starts on monday)
+func truncPeriod(chargeAt time.Time, groupBy string, location *time.Location) *time.Time {
+	date := chargeAt.In(location)
+	var period time.Time
+	switch groupBy {
+	case "day":
+		period = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
+	case "week":
+		weekday := (int(date.Weekday()) + 6) % 7
+		period = time.Date(date.Year(), date.Month(), date.Day() - weekday, 0, 0, 0, 0, location)
+	case "month":
+		period = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, location)
+	default:
+		return nil
+	}
+	return &period
+}
+
+// About the totals of the credits of an account of a tenant per currency (and per day, week or month)
+func (w *WorkerRepository) SummaryCredit(ctx context.Context, credit *model.AccountStatement, summaryFilter *model.SummaryFilter) (*[]model.CreditSummaryGroup, error){
+	childLogger.Info().Str("func","SummaryCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	state := w.readState()
+
+	location := time.UTC
+	if summaryFilter.Location != nil {
+		location = summaryFilter.Location
+	}
+
+	type groupKey struct {
+		period		int64
+		currency	string
+	}
+	groups := make(map[groupKey]*model.CreditSummaryGroup)
+	res_summary_list := []*model.CreditSummaryGroup{}
+
+	for _, row := range state.credits {
+		if !matchType(credit, &row) {
+			continue
+		}
+		if !summaryFilter.DateStart.IsZero() && row.ChargeAt.Before(summaryFilter.DateStart) {
+			continue
+		}
+		if !summaryFilter.DateEnd.IsZero() && !row.ChargeAt.Before(summaryFilter.DateEnd) {
+			continue
+		}
+
+		period := truncPeriod(row.ChargeAt, summaryFilter.GroupBy, location)
+		key := groupKey{currency: row.Currency}
+		if period != nil {
+			key.period = period.UnixNano()
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &model.CreditSummaryGroup{Period: period, Currency: row.Currency}
+			groups[key] = group
+			res_summary_list = append(res_summary_list, group)
+		}
+
+		var err error
+		group.NetAmount, err = group.NetAmount.Add(row.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if row.Type != credit.Type {
+			group.ReversalCount = group.ReversalCount + 1
+			group.ReversedAmount, err = group.ReversedAmount.Add(row.Amount.Abs())
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		group.Count = group.Count + 1
+		group.Amount, err = group.Amount.Add(row.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if group.MinAmount == nil || row.Amount.Cmp(*group.MinAmount) < 0 {
+			min_amount := row.Amount
+			group.MinAmount = &min_amount
+		}
+		if group.MaxAmount == nil || row.Amount.Cmp(*group.MaxAmount) > 0 {
+			max_amount := row.Amount
+			group.MaxAmount = &max_amount
+		}
+	}
+
+	res := []model.CreditSummaryGroup{}
+	for _, group := range res_summary_list {
+		if group.Count > 0 {
+			avg_amount, err := group.Amount.Quo(group.Count)
+			if err != nil {
+				return nil, err
+			}
+			group.AvgAmount = &avg_amount
+		}
+		res = append(res, *group)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Period != nil && res[j].Period != nil && !res[i].Period.Equal(*res[j].Period) {
+			return res[i].Period.Before(*res[j].Period)
+		}
+		return res[i].Currency < res[j].Currency
+	})
+
+	return &res, nil
+}
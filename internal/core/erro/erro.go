@@ -13,4 +13,25 @@ var (
 	ErrHTTPForbiden		= errors.New("forbiden request")
 	ErrTransInvalid		= errors.New("transaction invalid")
 	ErrInvalidAmount	= errors.New("invalid amount for this transaction type")
+	ErrIdempotencyConflict	= errors.New("idempotency key already used with a different request")
+	ErrAlreadyReversed	= errors.New("transaction already fully reversed")
+	ErrMoneyInvalid		= errors.New("invalid money value")
+	ErrMoneyOverflow	= errors.New("money value overflow")
+	ErrMoneyPrecision	= errors.New("money value exceeds the currency precision")
+	ErrCircuitOpen		= errors.New("circuit breaker open")
+	ErrInvalidAction	= errors.New("invalid action")
+	ErrInvalidParameter	= errors.New("invalid query parameter")
+	ErrInvalidDateRange	= errors.New("invalid date range")
+	ErrSchemaBehind		= errors.New("database schema version is behind")
+	ErrNoMigration		= errors.New("no migration to apply or roll back")
+	ErrHoldNotPending	= errors.New("hold is not pending")
+	ErrHoldExpired		= errors.New("hold expired")
+	ErrBatchTooLarge	= errors.New("batch exceeds the maximum number of items")
+	ErrBatchEmpty		= errors.New("batch without items")
+	ErrExportFormat		= errors.New("export format must be csv, ofx or camt053")
+	ErrExportCurrency	= errors.New("export format needs a currency")
+	ErrInvalidGroupBy	= errors.New("group_by must be day, week, month or currency")
+	ErrServiceUnavailable	= errors.New("service unavailable, the account can not be checked")
+	ErrInvalidTimeZone	= errors.New("tz must be a IANA time zone name (ex: America/Sao_Paulo)")
+	ErrOutboxClaimLost	= errors.New("outbox event claimed by another relay")
 )
\ No newline at end of file
@@ -11,7 +11,70 @@ type AppServer struct {
 	Server     		*Server     				`json:"server"`
 	ConfigOTEL		*go_core_observ.ConfigOTEL	`json:"otel_config"`
 	DatabaseConfig	*go_core_pg.DatabaseConfig  `json:"database"`
-	ApiService 		[]ApiService				`json:"api_endpoints"` 			
+	ApiService 		map[string]ApiService		`json:"api_endpoints"` 			
+	IdempotencyConfig	*IdempotencyConfig		`json:"idempotency_config"`
+	OutboxConfig	*OutboxConfig				`json:"outbox_config"`
+	CircuitBreakerConfig	[]CircuitBreakerConfig	`json:"circuit_breaker_config"`
+	AdminConfig		*AdminConfig				`json:"-"`
+	ListConfig		*ListConfig					`json:"list_config"`
+	MigrationConfig	*MigrationConfig			`json:"migration_config"`
+	HoldConfig		*HoldConfig					`json:"hold_config"`
+	BatchConfig		*BatchConfig				`json:"batch_config"`
+	AuthConfig		*AuthConfig					`json:"auth_config"`
+	BuildInfo		*BuildInfo					`json:"build_info"`
+	ConfigSource	*ConfigSource				`json:"config_source"`
+	HealthConfig	*HealthConfig				`json:"health_config"`
+	PropagatorConfig	*PropagatorConfig		`json:"propagator_config"`
+}
+
+type BuildInfo struct {
+	GoVersion		string	`json:"go_version"`
+	Module			string	`json:"module"`
+	Version			string	`json:"version"`
+	Revision		string	`json:"vcs_revision,omitempty"`
+	RevisionTime	string	`json:"vcs_time,omitempty"`
+	Modified		bool	`json:"vcs_modified,omitempty"`
+}
+
+type ConfigSource struct {
+	EnvFile			string	`json:"env_file,omitempty"`
+	Environment		bool	`json:"environment"`
+	SecretPath		string	`json:"secret_path"`
+	JwksFile		string	`json:"jwks_file,omitempty"`
+}
+
+type DependencyStatus struct {
+	Name			string	`json:"name"`
+	Type			string	`json:"type"`
+	Status			string	`json:"status"`
+	State			string	`json:"state,omitempty"`
+	Critical		bool	`json:"critical,omitempty"`
+	LatencyMs		int64	`json:"latency_ms,omitempty"`
+	Error			string	`json:"error,omitempty"`
+}
+
+type HealthConfig struct {
+	Timeout			int			`json:"timeout_ms"`
+	Critical		[]string	`json:"critical"`
+}
+
+// the propagators of the trace context (composite, in order), inbound (extract) and outbound (inject)
+type PropagatorConfig struct {
+	Propagators		[]string	`json:"propagators"`
+}
+
+// the readiness of the service, status up, degraded (a non critical component is failing) or down
+type HealthReport struct {
+	Status			string				`json:"status"`
+	CheckedAt		time.Time			`json:"checked_at"`
+	Components		[]DependencyStatus	`json:"components"`
+}
+
+type AppInfo struct {
+	BuildInfo		*BuildInfo			`json:"build_info"`
+	InfoPod			*InfoPod			`json:"info_pod"`
+	ConfigSource	*ConfigSource		`json:"config_source"`
+	Dependencies	[]DependencyStatus	`json:"dependencies"`
 }
 
 type InfoPod struct {
@@ -54,10 +117,14 @@ type AccountStatement struct {
 	Type			string  	`json:"type_charge,omitempty"`
 	ChargeAt		time.Time 	`json:"charged_at,omitempty"`
 	Currency		string  	`json:"currency,omitempty"`
-	Amount			float64 	`json:"amount,omitempty"`
+	Amount			Money 		`json:"amount"`
 	TenantID		string  	`json:"tenant_id,omitempty"`
 	TransactionID	*string  	`json:"transaction_id,transaction_id"`
 	Obs				string  	`json:"obs,omitempty"`
+	Metadata		map[string]interface{}	`json:"metadata,omitempty"`
+	RequestID		*string  	`json:"request_id,omitempty"`
+	ReversalOf		*string  	`json:"reversal_of,omitempty"`
+	ReversedAmount	*Money 		`json:"reversed_amount,omitempty"`
 }
 
 type ApiService struct {
@@ -65,6 +132,8 @@ type ApiService struct {
 	Url				string `json:"url"`
 	Method			string `json:"method"`
 	Header_x_apigw_api_id	string `json:"x-apigw-api-id"`
+	Timeout			int `json:"timeout"`
+	Retry			int `json:"retry"`
 }
 
 type Transfer struct {
@@ -72,9 +141,199 @@ type Transfer struct {
 	AccountFrom		*AccountStatement	`json:"account_from,omitempty"`
 	AccountTo		*AccountStatement	`json:"account_to,omitempty"`
 	Currency		string  	`json:"currency,omitempty"`
-	Amount			float64 	`json:"amount,omitempty"`
+	Amount			Money 		`json:"amount"`
 	TransferAt		time.Time 	`json:"transfer_at,omitempty"`
 	Type			string  	`json:"type_charge,omitempty"`
 	Status			string  	`json:"status,omitempty"`
 	TransactionID	*string  	`json:"transaction_id,omitempty"`
-}
\ No newline at end of file
+}
+
+type ListFilter struct {
+	Limit			int			`json:"limit,omitempty"`
+	Cursor			*string		`json:"cursor,omitempty"`
+	CursorChargeAt	time.Time	`json:"-"`
+	CursorID		int			`json:"-"`
+	Currency		string		`json:"currency,omitempty"`
+	AmountMin		*Money		`json:"amount_min,omitempty"`
+	AmountMax		*Money		`json:"amount_max,omitempty"`
+	DateStart		time.Time	`json:"date_start,omitempty"`
+	DateEnd			time.Time	`json:"date_end,omitempty"`
+	Metadata		map[string]string	`json:"metadata,omitempty"`
+}
+
+type ListConfig struct {
+	DefaultLimit	int `json:"default_limit"`
+	MaxLimit		int `json:"max_limit"`
+	MaxDateRange	int `json:"max_date_range"`
+}
+
+type AccountStatementPage struct {
+	Data			[]AccountStatement	`json:"data"`
+	NextCursor		*string				`json:"next_cursor,omitempty"`
+}
+
+type SummaryFilter struct {
+	DateStart		time.Time		`json:"from,omitempty"`
+	DateEnd			time.Time		`json:"to,omitempty"`
+	GroupBy			string			`json:"group_by"`
+	Location		*time.Location	`json:"-"`
+}
+
+type CreditSummary struct {
+	AccountID		string					`json:"account_id"`
+	DateStart		*time.Time				`json:"from,omitempty"`
+	DateEnd			*time.Time				`json:"to,omitempty"`
+	GroupBy			string					`json:"group_by"`
+	TimeZone		string					`json:"tz,omitempty"`
+	Data			[]CreditSummaryGroup	`json:"data"`
+}
+
+type CreditSummaryGroup struct {
+	Period			*time.Time	`json:"period,omitempty"`
+	Currency		string		`json:"currency"`
+	Count			int64		`json:"count"`
+	Amount			Money		`json:"amount"`
+	MinAmount		*Money		`json:"min_amount,omitempty"`
+	MaxAmount		*Money		`json:"max_amount,omitempty"`
+	AvgAmount		*Money		`json:"avg_amount,omitempty"`
+	ReversalCount	int64		`json:"reversal_count"`
+	ReversedAmount	Money		`json:"reversed_amount"`
+	NetAmount		Money		`json:"net_amount"`
+}
+
+type IdempotencyConfig struct {
+	ExpirationWindow	int `json:"expiration_window"`
+}
+
+type IdempotencyKey struct {
+	Key				string		`json:"idempotency_key"`
+	TenantID		string  	`json:"tenant_id,omitempty"`
+	RequestHash		string  	`json:"request_hash"`
+	StatusCode		int			`json:"status_code,omitempty"`
+	Response		[]byte		`json:"response,omitempty"`
+	CreatedAt		time.Time 	`json:"created_at,omitempty"`
+	ExpiresAt		time.Time 	`json:"expires_at,omitempty"`
+}
+
+type OutboxConfig struct {
+	PollInterval	int `json:"poll_interval"`
+	BatchSize		int `json:"batch_size"`
+	MaxAttempts		int `json:"max_attempts"`
+	BackoffBase		int `json:"backoff_base"`
+	BackoffMax		int `json:"backoff_max"`
+	ClaimTimeout	int `json:"claim_timeout"`
+}
+
+type OutboxEvent struct {
+	ID				int			`json:"id,omitempty"`
+	AggregateID		int			`json:"aggregate_id,omitempty"`
+	EventType		string  	`json:"event_type,omitempty"`
+	Payload			[]byte		`json:"payload,omitempty"`
+	Status			string  	`json:"status,omitempty"`
+	Attempts		int			`json:"attempts"`
+	NextAttemptAt	time.Time 	`json:"next_attempt_at,omitempty"`
+	LastError		*string  	`json:"last_error,omitempty"`
+	ClaimToken		*string  	`json:"claim_token,omitempty"`
+	CreatedAt		time.Time 	`json:"created_at,omitempty"`
+	UpdatedAt		*time.Time 	`json:"updated_at,omitempty"`
+}
+
+type HoldConfig struct {
+	TTL				int `json:"ttl"`
+	ExpiryInterval	int `json:"expiry_interval"`
+}
+
+// a two-phase credit, PENDING => CAPTURED (credit posted), VOIDED or EXPIRED
+type Hold struct {
+	ID				int			`json:"id,omitempty"`
+	FkAccountID		int			`json:"fk_account_id,omitempty"`
+	AccountID		string		`json:"account_id,omitempty"`
+	Currency		string  	`json:"currency,omitempty"`
+	Amount			Money 		`json:"amount"`
+	TenantID		string  	`json:"tenant_id,omitempty"`
+	TransactionID	*string  	`json:"transaction_id,omitempty"`
+	Status			string  	`json:"status,omitempty"`
+	Obs				string  	`json:"obs,omitempty"`
+	Metadata		map[string]interface{}	`json:"metadata,omitempty"`
+	FkCreditID		*int		`json:"fk_credit_id,omitempty"`
+	CreatedAt		time.Time 	`json:"created_at,omitempty"`
+	ExpiresAt		time.Time 	`json:"expires_at,omitempty"`
+	UpdatedAt		*time.Time 	`json:"updated_at,omitempty"`
+	Credit			*AccountStatement	`json:"credit,omitempty"`
+}
+
+type BatchConfig struct {
+	MaxItems		int `json:"max_items"`
+	MaxBytes		int64 `json:"max_bytes"`
+}
+
+// the result of a credit of a batch, status OK, ERROR or NOT_APPLIED (all-or-nothing batch with errors)
+type BatchItemResult struct {
+	Index			int			`json:"index"`
+	Status			string		`json:"status"`
+	ID				int			`json:"id,omitempty"`
+	TransactionID	*string		`json:"transaction_id,omitempty"`
+	Error			string		`json:"error,omitempty"`
+}
+
+type BatchResult struct {
+	Total			int			`json:"total"`
+	Succeeded		int			`json:"succeeded"`
+	Failed			int			`json:"failed"`
+	AllOrNothing	bool		`json:"all_or_nothing"`
+	Items			[]BatchItemResult	`json:"items"`
+}
+
+type CircuitBreakerConfig struct {
+	Name			string `json:"name"`
+	MaxRequests		uint32 `json:"max_requests"`
+	Interval		int `json:"interval"`
+	Timeout			int `json:"timeout"`
+	MaxFailures		uint32 `json:"max_failures"`
+}
+
+type CircuitBreakerCounts struct {
+	Requests				uint32 `json:"requests"`
+	TotalSuccesses			uint32 `json:"total_successes"`
+	TotalFailures			uint32 `json:"total_failures"`
+	ConsecutiveSuccesses	uint32 `json:"consecutive_successes"`
+	ConsecutiveFailures		uint32 `json:"consecutive_failures"`
+}
+
+type CircuitBreakerStatus struct {
+	Name			string					`json:"name"`
+	State			string					`json:"state"`
+	Counts			CircuitBreakerCounts	`json:"counts"`
+	LastTransition	time.Time				`json:"last_transition"`
+}
+
+type AdminConfig struct {
+	Token			string `json:"-"`
+}
+
+type AuthConfig struct {
+	Enabled			bool	`json:"enabled"`
+	JwksFile		string	`json:"jwks_file"`
+	Issuer			string	`json:"issuer,omitempty"`
+	Audience		string	`json:"audience,omitempty"`
+	Leeway			int		`json:"leeway"`
+	DevTenantHeader	bool	`json:"dev_tenant_header"`
+}
+
+// the caller of a request, from the claims of the jwt
+type Principal struct {
+	Subject			string		`json:"sub,omitempty"`
+	TenantID		string		`json:"tenant_id,omitempty"`
+	Scopes			[]string	`json:"scopes,omitempty"`
+}
+
+type MigrationConfig struct {
+	SchemaCheck		bool		`json:"schema_check"`
+}
+
+type MigrationStatus struct {
+	Version			int			`json:"version"`
+	Name			string		`json:"name"`
+	Applied			bool		`json:"applied"`
+	AppliedAt		*time.Time	`json:"applied_at,omitempty"`
+}
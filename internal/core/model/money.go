@@ -0,0 +1,303 @@
+package model
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/go-credit/internal/core/erro"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// currencies with a minor unit different from 2 (ISO 4217)
+var currencyScale = map[string]int32{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// Money is an exact decimal amount, the value is units * 10^-scale
+type Money struct {
+	units	int64
+	scale	int32
+}
+
+// About the number of decimal places of a currency (2 when unknown)
+func CurrencyScale(currency string) int32 {
+	if scale, ok := currencyScale[strings.ToUpper(currency)]; ok {
+		return scale
+	}
+	return 2
+}
+
+// About create a money from minor units (ex: 1050 with scale 2 is 10.50)
+func NewMoney(units int64, scale int32) Money {
+	return Money{units: units, scale: scale}
+}
+
+// About create a money from the minor units of a currency
+func NewMoneyFromMinorUnits(units int64, currency string) Money {
+	return Money{units: units, scale: CurrencyScale(currency)}
+}
+
+// About parse a decimal string without losing precision (ex: "-10.50")
+func ParseMoney(value string) (Money, error) {
+	str := strings.TrimSpace(value)
+	if str == "" {
+		return Money{}, erro.ErrMoneyInvalid
+	}
+
+	negative := false
+	if str[0] == '-' || str[0] == '+' {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+
+	integer, fraction, hasPoint := strings.Cut(str, ".")
+	if integer == "" && fraction == "" {
+		return Money{}, erro.ErrMoneyInvalid
+	}
+	if hasPoint && fraction == "" {
+		return Money{}, erro.ErrMoneyInvalid
+	}
+	for _, c := range integer + fraction {
+		if c < '0' || c > '9' {
+			return Money{}, erro.ErrMoneyInvalid
+		}
+	}
+
+	digits := strings.TrimLeft(integer + fraction, "0")
+	if digits == "" {
+		digits = "0"
+	}
+	units, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, erro.ErrMoneyOverflow
+	}
+	if negative {
+		units = -units
+	}
+
+	return Money{units: units, scale: int32(len(fraction))}, nil
+}
+
+// About the scale (number of decimal places)
+func (m Money) Scale() int32 {
+	return m.scale
+}
+
+// About the value in minor units of a currency
+func (m Money) MinorUnits(currency string) (int64, error) {
+	res, err := m.Rescale(CurrencyScale(currency))
+	if err != nil {
+		return 0, err
+	}
+	return res.units, nil
+}
+
+// About change the scale, it fails when a non zero digit would be lost
+func (m Money) Rescale(scale int32) (Money, error) {
+	units := m.units
+	for s := m.scale; s < scale; s++ {
+		if units > math.MaxInt64 / 10 || units < math.MinInt64 / 10 {
+			return Money{}, erro.ErrMoneyOverflow
+		}
+		units = units * 10
+	}
+	for s := m.scale; s > scale; s-- {
+		if units % 10 != 0 {
+			return Money{}, erro.ErrMoneyPrecision
+		}
+		units = units / 10
+	}
+	return Money{units: units, scale: scale}, nil
+}
+
+// About rescale to the precision of a currency
+func (m Money) ForCurrency(currency string) (Money, error) {
+	return m.Rescale(CurrencyScale(currency))
+}
+
+// About align two values to the same scale
+func align(a, b Money) (Money, Money, error) {
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+	a, err := a.Rescale(scale)
+	if err != nil {
+		return Money{}, Money{}, err
+	}
+	b, err = b.Rescale(scale)
+	if err != nil {
+		return Money{}, Money{}, err
+	}
+	return a, b, nil
+}
+
+// About a + b
+func (m Money) Add(b Money) (Money, error) {
+	x, y, err := align(m, b)
+	if err != nil {
+		return Money{}, err
+	}
+	if (y.units > 0 && x.units > math.MaxInt64 - y.units) || (y.units < 0 && x.units < math.MinInt64 - y.units) {
+		return Money{}, erro.ErrMoneyOverflow
+	}
+	return Money{units: x.units + y.units, scale: x.scale}, nil
+}
+
+// About a - b
+func (m Money) Sub(b Money) (Money, error) {
+	return m.Add(b.Neg())
+}
+
+// About -a
+func (m Money) Neg() Money {
+	return Money{units: -m.units, scale: m.scale}
+}
+
+// About |a|
+func (m Money) Abs() Money {
+	if m.units < 0 {
+		return m.Neg()
+	}
+	return m
+}
+
+// About a / n at the scale of a, halves are rounded away from zero (as the numeric round)
+func (m Money) Quo(n int64) (Money, error) {
+	if n == 0 {
+		return Money{}, erro.ErrInvalidAmount
+	}
+	quo := new(big.Rat).Quo(m.bigRat(), new(big.Rat).SetInt64(n))
+	return ParseMoney(quo.FloatString(int(m.scale)))
+}
+
+// About compare a and b (-1, 0, 1)
+func (m Money) Cmp(b Money) int {
+	x, y, err := align(m, b)
+	if err != nil {
+		// only an overflow fails, compare using big numbers
+		return m.bigRat().Cmp(b.bigRat())
+	}
+	switch {
+	case x.units < y.units:
+		return -1
+	case x.units > y.units:
+		return 1
+	}
+	return 0
+}
+
+// About the sign (-1, 0, 1)
+func (m Money) Sign() int {
+	switch {
+	case m.units < 0:
+		return -1
+	case m.units > 0:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) IsZero() bool {
+	return m.units == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.units < 0
+}
+
+func (m Money) bigRat() *big.Rat {
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(m.scale)), nil)
+	return new(big.Rat).SetFrac(big.NewInt(m.units), denom)
+}
+
+// About the approximate value as a float (metrics only, never for arithmetic)
+func (m Money) Float64() float64 {
+	value, _ := m.bigRat().Float64()
+	return value
+}
+
+// About the decimal representation (ex: "10.50")
+func (m Money) String() string {
+	str := strconv.FormatInt(m.units, 10)
+	negative := strings.HasPrefix(str, "-")
+	str = strings.TrimPrefix(str, "-")
+
+	if m.scale > 0 {
+		if len(str) <= int(m.scale) {
+			str = strings.Repeat("0", int(m.scale) - len(str) + 1) + str
+		}
+		point := len(str) - int(m.scale)
+		str = str[:point] + "." + str[point:]
+	}
+	if negative {
+		str = "-" + str
+	}
+	return str
+}
+
+// About json encoding as a string to keep the exact value
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(m.String())), nil
+}
+
+// About json decoding, accepts a string ("10.50") or a number (10.50)
+func (m *Money) UnmarshalJSON(data []byte) error {
+	str := string(data)
+	if str == "null" {
+		*m = Money{}
+		return nil
+	}
+	if strings.HasPrefix(str, `"`) {
+		unquoted, err := strconv.Unquote(str)
+		if err != nil {
+			return erro.ErrMoneyInvalid
+		}
+		str = unquoted
+	}
+
+	res, err := ParseMoney(str)
+	if err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
+
+// About pgx scan from NUMERIC
+func (m *Money) ScanNumeric(v pgtype.Numeric) error {
+	if !v.Valid {
+		*m = Money{}
+		return nil
+	}
+	if v.NaN || v.InfinityModifier != pgtype.Finite {
+		return erro.ErrMoneyInvalid
+	}
+	if !v.Int.IsInt64() {
+		return erro.ErrMoneyOverflow
+	}
+
+	res := Money{units: v.Int.Int64()}
+	if v.Exp < 0 {
+		res.scale = -v.Exp
+		*m = res
+		return nil
+	}
+	for i := int32(0); i < v.Exp; i++ {
+		if res.units > math.MaxInt64 / 10 || res.units < math.MinInt64 / 10 {
+			return erro.ErrMoneyOverflow
+		}
+		res.units = res.units * 10
+	}
+	*m = res
+	return nil
+}
+
+// About pgx bind to NUMERIC
+func (m Money) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(m.units), Exp: -m.scale, Valid: true}, nil
+}
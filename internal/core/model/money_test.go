@@ -0,0 +1,296 @@
+package model_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/core/model"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func mustParseMoney(t *testing.T, value string) model.Money {
+	t.Helper()
+
+	money, err := model.ParseMoney(value)
+	if err != nil {
+		t.Fatalf("parse money %q: %v", value, err)
+	}
+	return money
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value		string
+		want		string
+		wantScale	int32
+		wantErr		error
+	}{
+		{"10.50", "10.50", 2, nil},
+		{"+10.5", "10.5", 1, nil},
+		{"-10.50", "-10.50", 2, nil},
+		{" 7 ", "7", 0, nil},
+		{"007.50", "7.50", 2, nil},
+		{"-000.05", "-0.05", 2, nil},
+		{".5", "0.5", 1, nil},
+		{"0", "0", 0, nil},
+		{"9223372036854775807", "9223372036854775807", 0, nil},
+		{"1.", "", 0, erro.ErrMoneyInvalid},
+		{".", "", 0, erro.ErrMoneyInvalid},
+		{"", "", 0, erro.ErrMoneyInvalid},
+		{"-", "", 0, erro.ErrMoneyInvalid},
+		{"--1", "", 0, erro.ErrMoneyInvalid},
+		{"1,50", "", 0, erro.ErrMoneyInvalid},
+		{"1e3", "", 0, erro.ErrMoneyInvalid},
+		{"9223372036854775808", "", 0, erro.ErrMoneyOverflow},
+		{"92233720368547758.08", "", 0, erro.ErrMoneyOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			res, err := model.ParseMoney(tt.value)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if res.String() != tt.want || res.Scale() != tt.wantScale {
+				t.Errorf("got %s (scale %d), want %s (scale %d)", res, res.Scale(), tt.want, tt.wantScale)
+			}
+		})
+	}
+}
+
+func TestMoneyRescale(t *testing.T) {
+	tests := []struct {
+		value	string
+		scale	int32
+		want	string
+		wantErr	error
+	}{
+		{"10.5", 2, "10.50", nil},
+		{"10.500", 2, "10.50", nil},
+		{"-10", 2, "-10.00", nil},
+		{"10.50", 0, "", erro.ErrMoneyPrecision},
+		{"10.505", 2, "", erro.ErrMoneyPrecision},
+		{"-0.001", 2, "", erro.ErrMoneyPrecision},
+		{"92233720368547758.07", 3, "", erro.ErrMoneyOverflow},
+		{"-92233720368547758.07", 3, "", erro.ErrMoneyOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			res, err := mustParseMoney(t, tt.value).Rescale(tt.scale)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && res.String() != tt.want {
+				t.Errorf("got %s, want %s", res, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyForCurrency(t *testing.T) {
+	tests := []struct {
+		value		string
+		currency	string
+		want		string
+		wantErr		error
+	}{
+		{"10.5", "BRL", "10.50", nil},
+		{"1000", "JPY", "1000", nil},
+		{"1000.5", "JPY", "", erro.ErrMoneyPrecision},
+		{"1.5", "KWD", "1.500", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value + " " + tt.currency, func(t *testing.T) {
+			res, err := mustParseMoney(t, tt.value).ForCurrency(tt.currency)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && res.String() != tt.want {
+				t.Errorf("got %s, want %s", res, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyAdd(t *testing.T) {
+	tests := []struct {
+		a, b	string
+		want	string
+		wantErr	error
+	}{
+		{"10.50", "0.5", "11.00", nil},
+		{"10.50", "-20", "-9.50", nil},
+		{"9223372036854775807", "-1", "9223372036854775806", nil},
+		{"9223372036854775807", "1", "", erro.ErrMoneyOverflow},
+		{"-9223372036854775807", "-2", "", erro.ErrMoneyOverflow},
+		{"92233720368547758.07", "0.001", "", erro.ErrMoneyOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a + "+" + tt.b, func(t *testing.T) {
+			res, err := mustParseMoney(t, tt.a).Add(mustParseMoney(t, tt.b))
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && res.String() != tt.want {
+				t.Errorf("got %s, want %s", res, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyQuo(t *testing.T) {
+	tests := []struct {
+		value	string
+		n		int64
+		want	string
+		wantErr	error
+	}{
+		{"10.00", 4, "2.50", nil},
+		{"10.00", 3, "3.33", nil},
+		{"20.00", 3, "6.67", nil},
+		{"0.05", 2, "0.03", nil},
+		{"-0.05", 2, "-0.03", nil},
+		{"0.05", -2, "-0.03", nil},
+		{"-20.00", 3, "-6.67", nil},
+		{"-0.01", 3, "0.00", nil},
+		{"10.00", 0, "", erro.ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			res, err := mustParseMoney(t, tt.value).Quo(tt.n)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && res.String() != tt.want {
+				t.Errorf("%s / %d = %s, want %s", tt.value, tt.n, res, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money	model.Money
+		want	string
+	}{
+		{model.NewMoney(5, 2), "0.05"},
+		{model.NewMoney(-5, 2), "-0.05"},
+		{model.NewMoney(50, 2), "0.50"},
+		{model.NewMoney(0, 2), "0.00"},
+		{model.NewMoney(1050, 2), "10.50"},
+		{model.NewMoney(-1050, 2), "-10.50"},
+		{model.NewMoney(7, 0), "7"},
+		{model.NewMoney(1, 3), "0.001"},
+		{model.NewMoney(math.MinInt64, 2), "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.money.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		json	string
+		want	string
+		wantErr	error
+	}{
+		{`"10.50"`, "10.50", nil},
+		{`10.50`, "10.50", nil},
+		{`"-0.05"`, "-0.05", nil},
+		{`null`, "0", nil},
+		{`"abc"`, "", erro.ErrMoneyInvalid},
+		{`"1."`, "", erro.ErrMoneyInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			var money model.Money
+			err := json.Unmarshal([]byte(tt.json), &money)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if money.String() != tt.want {
+				t.Fatalf("got %s, want %s", money, tt.want)
+			}
+
+			// encoded as a string, the scale is kept
+			data, err := json.Marshal(money)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			var res model.Money
+			err = json.Unmarshal(data, &res)
+			if err != nil {
+				t.Fatalf("unmarshal %s: %v", data, err)
+			}
+			if res != money {
+				t.Errorf("round-trip %s = %s (scale %d), want %s (scale %d)", data, res, res.Scale(), money, money.Scale())
+			}
+		})
+	}
+}
+
+func TestMoneyScanNumeric(t *testing.T) {
+	tests := []struct {
+		name		string
+		numeric		pgtype.Numeric
+		want		string
+		wantScale	int32
+		wantErr		error
+	}{
+		{"negative exp", pgtype.Numeric{Int: big.NewInt(1050), Exp: -2, Valid: true}, "10.50", 2, nil},
+		{"negative value", pgtype.Numeric{Int: big.NewInt(-5), Exp: -2, Valid: true}, "-0.05", 2, nil},
+		{"zero exp", pgtype.Numeric{Int: big.NewInt(7), Exp: 0, Valid: true}, "7", 0, nil},
+		{"positive exp", pgtype.Numeric{Int: big.NewInt(5), Exp: 2, Valid: true}, "500", 0, nil},
+		{"positive exp negative value", pgtype.Numeric{Int: big.NewInt(-5), Exp: 3, Valid: true}, "-5000", 0, nil},
+		{"null", pgtype.Numeric{}, "0", 0, nil},
+		{"nan", pgtype.Numeric{NaN: true, Valid: true}, "", 0, erro.ErrMoneyInvalid},
+		{"positive exp overflow", pgtype.Numeric{Int: big.NewInt(10), Exp: 19, Valid: true}, "", 0, erro.ErrMoneyOverflow},
+		{"int overflow", pgtype.Numeric{Int: new(big.Int).Lsh(big.NewInt(1), 64), Exp: 0, Valid: true}, "", 0, erro.ErrMoneyOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var money model.Money
+			err := money.ScanNumeric(tt.numeric)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if money.String() != tt.want || money.Scale() != tt.wantScale {
+				t.Errorf("got %s (scale %d), want %s (scale %d)", money, money.Scale(), tt.want, tt.wantScale)
+			}
+
+			// the bound value scans back to the same money
+			numeric, err := money.NumericValue()
+			if err != nil {
+				t.Fatalf("numeric value: %v", err)
+			}
+			var res model.Money
+			if err = res.ScanNumeric(numeric); err != nil || res != money {
+				t.Errorf("round-trip = %s, %v, want %s", res, err, money)
+			}
+		})
+	}
+}
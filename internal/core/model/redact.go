@@ -0,0 +1,53 @@
+package model
+
+import (
+	"net/url"
+)
+
+// the value shown in place of a secret
+const RedactedValue = "********"
+
+// About mask a secret, an empty value stays empty
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	return RedactedValue
+}
+
+// About mask the password of a url (user:password@host)
+func redactUrl(value string) string {
+	u, err := url.Parse(value)
+	if err != nil {
+		return redact(value)
+	}
+	return u.Redacted()
+}
+
+// About a copy of the app server safe to expose or log, the database credentials,
+// the api gw ids and the url passwords are masked
+func (a AppServer) Redacted() AppServer {
+	if a.DatabaseConfig != nil {
+		databaseConfig := *a.DatabaseConfig
+		databaseConfig.User = redact(databaseConfig.User)
+		databaseConfig.Password = redact(databaseConfig.Password)
+		a.DatabaseConfig = &databaseConfig
+	}
+	if a.ConfigOTEL != nil {
+		configOTEL := *a.ConfigOTEL
+		configOTEL.OtelExportEndpoint = redactUrl(configOTEL.OtelExportEndpoint)
+		a.ConfigOTEL = &configOTEL
+	}
+	if a.ApiService != nil {
+		apiService := make(map[string]ApiService, len(a.ApiService))
+		for name, endpoint := range a.ApiService {
+			endpoint.Url = redactUrl(endpoint.Url)
+			endpoint.Header_x_apigw_api_id = redact(endpoint.Header_x_apigw_api_id)
+			apiService[name] = endpoint
+		}
+		a.ApiService = apiService
+	}
+	a.AdminConfig = nil
+
+	return a
+}
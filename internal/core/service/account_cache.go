@@ -0,0 +1,90 @@
+package service
+
+import(
+	"context"
+	"sync"
+
+	"github.com/go-credit/internal/core/model"
+)
+
+// the max accounts kept by the cache, a full cache starts over
+const accountCacheSize = 10000
+
+// the last known accounts of go-account (the tenant and the account id of an account do not change).
+// When go-account is unavailable the tenant of an account is checked with it
+type accountCache struct {
+	mutex		sync.RWMutex
+	accounts	map[string]model.Account
+	ids			map[int]string
+}
+
+func newAccountCache() *accountCache {
+	return &accountCache{	accounts: map[string]model.Account{},
+							ids: map[int]string{},
+	}
+}
+
+// About the last known account of a account id
+func (c *accountCache) get(accountID string) (*model.Account, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	account, ok := c.accounts[accountID]
+	if !ok {
+		return nil, false
+	}
+	return &account, true
+}
+
+// About the last known account of a id (pk)
+func (c *accountCache) getByID(id int) (*model.Account, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	accountID, ok := c.ids[id]
+	if !ok {
+		return nil, false
+	}
+	account := c.accounts[accountID]
+	return &account, true
+}
+
+// About keep a account read from go-account
+func (c *accountCache) put(account *model.Account) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.accounts) >= accountCacheSize {
+		c.accounts = map[string]model.Account{}
+		c.ids = map[int]string{}
+	}
+	c.accounts[account.AccountID] = *account
+	c.ids[account.ID] = account.AccountID
+}
+
+// About get a account from go-account and keep it in the cache
+func (s *WorkerService) getAccount(ctx context.Context, accountID string) (*model.Account, error){
+	res_account, err := s.accountClient.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	s.accountCache.put(res_account)
+
+	return res_account, nil
+}
+
+// About get a account by id (pk), from the cache or from go-account
+func (s *WorkerService) getAccountByID(ctx context.Context, id int) (*model.Account, error){
+	res_cached, ok := s.accountCache.getByID(id)
+	if ok {
+		return res_cached, nil
+	}
+
+	res_account, err := s.accountClient.GetAccountByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.accountCache.put(res_account)
+
+	return res_account, nil
+}
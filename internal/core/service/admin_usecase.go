@@ -0,0 +1,65 @@
+package service
+
+import(
+	"context"
+
+	"github.com/go-credit/internal/core/model"
+)
+
+// About list the circuit breakers status
+func (s *WorkerService) ListCircuitBreaker(ctx context.Context) (*[]model.CircuitBreakerStatus, error){
+	childLogger.Info().Str("func","ListCircuitBreaker").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListCircuitBreaker")
+	defer span.End()
+
+	res := s.circuitBreakers.List()
+
+	return &res, nil
+}
+
+// About force the state of a circuit breaker (open, close, reset)
+func (s *WorkerService) SetCircuitBreaker(ctx context.Context, name string, action string) (*model.CircuitBreakerStatus, error){
+	childLogger.Info().Str("func","SetCircuitBreaker").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("name", name).Str("action", action).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.SetCircuitBreaker")
+	defer span.End()
+
+	return s.circuitBreakers.SetState(name, action)
+}
+
+// About the status of the downstream services from the state of their breakers (no call is made)
+func (s *WorkerService) ListDependency(ctx context.Context) (*[]model.DependencyStatus, error){
+	childLogger.Info().Str("func","ListDependency").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListDependency")
+	defer span.End()
+
+	res := s.endpointDependency()
+
+	return &res, nil
+}
+
+// About the status of an endpoint from the state of its breaker, an open breaker is down
+func (s *WorkerService) endpointDependency() []model.DependencyStatus{
+	res := []model.DependencyStatus{}
+	for _, circuitBreaker := range s.circuitBreakers.List() {
+		dependency := model.DependencyStatus{	Name: circuitBreaker.Name,
+												Type: "endpoint",
+												State: circuitBreaker.State,
+		}
+		switch circuitBreaker.State {
+		case "open", "forced-open":
+			dependency.Status = "down"
+		case "half-open":
+			dependency.Status = "degraded"
+		default:
+			dependency.Status = "up"
+		}
+		res = append(res, dependency)
+	}
+	return res
+}
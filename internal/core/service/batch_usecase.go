@@ -0,0 +1,152 @@
+package service
+
+import(
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/infra/telemetry"
+)
+
+// About validate a credit of a batch and set its account (the accounts are looked up once per batch)
+func (s *WorkerService) validateBatchCredit(ctx context.Context,
+											credit *model.AccountStatement,
+											accounts map[string]*model.Account,
+											accountErrors map[string]error) error{
+	// Business rules, the same of /add
+	err := validateCredit(credit)
+	if err != nil {
+		return err
+	}
+
+	// Get the Account ID from Account-service
+	if err, ok := accountErrors[credit.AccountID]; ok {
+		return err
+	}
+	res_account, ok := accounts[credit.AccountID]
+	if !ok {
+		res_account, err = s.getAccount(ctx, credit.AccountID)
+		if err != nil {
+			accountErrors[credit.AccountID] = err
+			return err
+		}
+		accounts[credit.AccountID] = res_account
+	}
+	err = checkAccountTenant(res_account, credit.TenantID)
+	if err != nil {
+		return err
+	}
+	credit.FkAccountID = res_account.ID
+
+	return nil
+}
+
+// About add a batch of credits, a invalid credit is reported (partial success) or rejects the whole batch (all-or-nothing)
+func (s *WorkerService) AddCreditBatch(ctx context.Context, credits []model.AccountStatement, allOrNothing bool) (*model.BatchResult, error){
+	childLogger.Info().Str("func","AddCreditBatch").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("credits", len(credits)).Bool("all_or_nothing", allOrNothing).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddCreditBatch")
+
+	if len(credits) == 0 {
+		telemetry.RecordError(span, erro.ErrBatchEmpty)
+		span.End()
+		return nil, erro.ErrBatchEmpty
+	}
+	if len(credits) > s.batchConfig.MaxItems {
+		telemetry.RecordError(span, erro.ErrBatchTooLarge)
+		span.End()
+		return nil, erro.ErrBatchTooLarge
+	}
+
+	// Validate every credit
+	res_batch := model.BatchResult{	Total: len(credits),
+									AllOrNothing: allOrNothing,
+									Items: make([]model.BatchItemResult, len(credits)),
+	}
+	accounts := make(map[string]*model.Account)
+	accountErrors := make(map[string]error)
+	valid := []model.AccountStatement{}
+	validIndex := []int{}
+
+	for i := range credits {
+		res_batch.Items[i].Index = i
+
+		errValidate := s.validateBatchCredit(ctx, &credits[i], accounts, accountErrors)
+		if errValidate != nil {
+			res_batch.Items[i].Status = "ERROR"
+			res_batch.Items[i].Error = errValidate.Error()
+			res_batch.Failed = res_batch.Failed + 1
+			continue
+		}
+		valid = append(valid, credits[i])
+		validIndex = append(validIndex, i)
+	}
+
+	if len(valid) == 0 || (allOrNothing && res_batch.Failed > 0) {
+		for _, i := range validIndex {
+			res_batch.Items[i].Status = "NOT_APPLIED"
+		}
+		span.End()
+		return &res_batch, nil
+	}
+
+	// Get the database connection
+	tx, conn, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction, the rollback is a no-op after the commit
+	defer func() {
+		if err != nil {
+			telemetry.RecordError(span, err)
+		}
+		tx.Rollback(ctx)
+		s.workerRepository.ReleaseTx(conn)
+		span.End()
+	}()
+
+	// Add the credits (create account_statement)
+	res_credits, err := s.workerRepository.AddCreditBatch(ctx, tx, valid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the outbox events in the same transaction, the relay will update the balances in go-account
+	outboxEvents := make([]model.OutboxEvent, 0, len(res_credits))
+	for i := range res_credits {
+		payload, errMarshal := json.Marshal(res_credits[i])
+		if errMarshal != nil {
+			err = errors.New(errMarshal.Error())
+			return nil, err
+		}
+		outboxEvents = append(outboxEvents, model.OutboxEvent{	AggregateID: res_credits[i].ID,
+																EventType: "CREDIT-BALANCE",
+																Payload: payload,
+		})
+	}
+	_, err = s.workerRepository.AddOutboxEventBatch(ctx, tx, outboxEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range validIndex {
+		res_batch.Items[i].Status = "OK"
+		res_batch.Items[i].ID = res_credits[j].ID
+		res_batch.Items[i].TransactionID = res_credits[j].TransactionID
+		res_batch.Succeeded = res_batch.Succeeded + 1
+	}
+
+	// Commit, the request fails when the commit fails
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return &res_batch, nil
+}
@@ -0,0 +1,22 @@
+package service
+
+import(
+	"context"
+
+	"github.com/go-credit/internal/core/model"
+)
+
+// the go-account calls used by the worker service
+type AccountClient interface {
+	// account by account id (ACC-1)
+	GetAccount(ctx context.Context, accountID string) (*model.Account, error)
+	// account by id (pk)
+	GetAccountByID(ctx context.Context, id int) (*model.Account, error)
+	// update the balance, the request id lets go-account discard a redelivery
+	AddAccountBalance(ctx context.Context, credit *model.AccountStatement, requestID string) error
+}
+
+// the go-fund-transfer calls used by the worker service
+type TransferClient interface {
+	CreditTransfer(ctx context.Context, transfer *model.Transfer) error
+}
@@ -2,35 +2,111 @@ package service
 
 import(
 	"fmt"
+	"time"
 	"context"
 	"net/http"
 	"encoding/json"
+	"encoding/hex"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"crypto/sha256"
 	"errors"
 
-	"github.com/go-credit/internal/infra/circuitbreaker"
 	"github.com/go-credit/internal/core/model"
 	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/infra/telemetry"
 	go_core_observ "github.com/eliezerraj/go-core/observability"
-	go_core_api "github.com/eliezerraj/go-core/api"
+
+	"github.com/jackc/pgx/v5"
 )
 
 var tracerProvider go_core_observ.TracerProvider
-var apiService go_core_api.ApiService
-
-// About handle/convert http status code
-func errorStatusCode(statusCode int) error{
-	var err error
-	switch statusCode {
-	case http.StatusUnauthorized:
-		err = erro.ErrUnauthorized
-	case http.StatusForbidden:
-		err = erro.ErrHTTPForbiden
-	case http.StatusNotFound:
-		err = erro.ErrNotFound
-	default:
-		err = erro.ErrServer
+
+// About hash a credit request (the request id itself is not part of the hash)
+func requestHash(credit *model.AccountStatement) (string, error){
+	request := *credit
+	request.RequestID = nil
+
+	jsonString, err := json.Marshal(request)
+	if err != nil {
+		return "", errors.New(err.Error())
 	}
-	return err
+	hash := sha256.Sum256(jsonString)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// About replay the stored result of a idempotency key
+func replayIdempotencyKey(idempotencyKey *model.IdempotencyKey, request_hash string) (*model.AccountStatement, error){
+	childLogger.Info().Str("func","replayIdempotencyKey").Str("idempotency_key", idempotencyKey.Key).Send()
+
+	if idempotencyKey.RequestHash != request_hash || idempotencyKey.StatusCode == 0 {
+		return nil, erro.ErrIdempotencyConflict
+	}
+
+	var credit model.AccountStatement
+	err := json.Unmarshal(idempotencyKey.Response, &credit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return &credit, nil
+}
+
+// About check the idempotency key of a request, a replay returns the stored result, otherwise the key
+// to reserve (nil without request id). The hash is of the normalized request, 10.5 and 10.50 are the same request
+func (s *WorkerService) checkIdempotencyKey(ctx context.Context, credit *model.AccountStatement) (*model.IdempotencyKey, *model.AccountStatement, error){
+	if credit.RequestID == nil || *credit.RequestID == "" {
+		return nil, nil, nil
+	}
+
+	request_hash, err := requestHash(credit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res_idempotencyKey, err := s.workerRepository.GetIdempotencyKey(ctx, credit.TenantID, *credit.RequestID)
+	if err != nil && err != erro.ErrNotFound {
+		return nil, nil, err
+	}
+	if res_idempotencyKey != nil {
+		res_replay, err := replayIdempotencyKey(res_idempotencyKey, request_hash)
+		return nil, res_replay, err
+	}
+
+	idempotencyKey := &model.IdempotencyKey{	Key: *credit.RequestID,
+												TenantID: credit.TenantID,
+												RequestHash: request_hash,
+												ExpiresAt: time.Now().Add(time.Duration(s.idempotencyConfig.ExpirationWindow) * time.Second),
+	}
+	return idempotencyKey, nil, nil
+}
+
+// About the business rules of a credit of /add and /add/batch, the amount is set to the precision of the currency
+func validateCredit(credit *model.AccountStatement) error{
+	if credit.Type != "CREDIT" {
+		return erro.ErrTransInvalid
+	}
+	if credit.Amount.IsNegative() {
+		return erro.ErrInvalidAmount
+	}
+	amount, err := credit.Amount.ForCurrency(credit.Currency)
+	if err != nil {
+		return erro.ErrInvalidAmount
+	}
+	credit.Amount = amount
+
+	return nil
+}
+
+// About refuse an account of another tenant
+func checkAccountTenant(account *model.Account, tenantID string) error{
+	if tenantID == "" || account.TenantID != tenantID {
+		childLogger.Warn().Str("account_id", account.AccountID).Str("tenant_id", tenantID).Msg("cross-tenant access refused")
+		return erro.ErrHTTPForbiden
+	}
+	return nil
 }
 
 // About add credit
@@ -39,194 +115,420 @@ func (s *WorkerService) AddCredit(ctx context.Context, credit *model.AccountStat
 
 	// Trace
 	span := tracerProvider.Span(ctx, "service.AddCredit")
-	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+	telemetry.SetCreditAttributes(span, credit)
+
+	// Business rule, the tenant comes from the caller
+	if credit.TenantID == "" {
+		telemetry.RecordError(span, erro.ErrInvalidParameter)
+		span.End()
+		return nil, erro.ErrInvalidParameter
+	}
+
+	// Business rules
+	err := validateCredit(credit)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	// Check the idempotency key, a replay returns the original result
+	idempotencyKey, res_replay, err := s.checkIdempotencyKey(ctx, credit)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+	if res_replay != nil {
+		span.End()
+		return res_replay, nil
+	}
+
+	// Get the Account ID (PK) from Account-service, before the transaction
+	res_account, err := s.getAccount(ctx, credit.AccountID)
+	if err == erro.ErrCircuitOpen {
+		res_fallback, err := s.addCreditCircuitOpen(ctx, credit, idempotencyKey)
+		if err != nil {
+			telemetry.RecordError(span, err)
+			span.End()
+			return nil, err
+		}
+		span.End()
+		return res_fallback, nil
+	}
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	// Business rule
+	err = checkAccountTenant(res_account, credit.TenantID)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+	credit.FkAccountID = res_account.ID
 
 	// Get the database connection
-	tx, conn, err := s.workerRepository.DatabasePGServer.StartTx(ctx)
+	tx, conn, err := s.workerRepository.StartTx(ctx)
 	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
 		return nil, err
 	}
 	
-	// Handle the transaction
+	// Handle the transaction, the rollback is a no-op after the commit
 	defer func() {
 		if err != nil {
-			tx.Rollback(ctx)
-		} else {
-			tx.Commit(ctx)
+			telemetry.RecordError(span, err)
 		}
-		s.workerRepository.DatabasePGServer.ReleaseTx(conn)
+		tx.Rollback(ctx)
+		s.workerRepository.ReleaseTx(conn)
 		span.End()
 	}()
 
-	//Open CB - MOCK
-	circuitBreaker := circuitbreaker.CircuitBreakerConfig()
-	_, err = circuitBreaker.Execute(func() (interface{}, error) {
-		if credit.Type == "CREDIT-CB" {
-			return nil, erro.ErrTransInvalid
-		}
-		return nil , nil
-	})
-	if (err != nil) {
-		spanCB := tracerProvider.Span(ctx, "service.AddCredit-CIRCUIT-BREAKER")
-
-		childLogger.Error().Err(err).Interface("trace-resquest-id", ctx.Value("trace-request-id")).Msg(" ****** Circuit Breaker OPEN !!! ******")
-
-		transfer := model.Transfer{}
-		transfer.Currency = credit.Currency
-		transfer.Amount = credit.Amount
-		transfer.Type = "CREDIT"
-		transfer.AccountFrom = credit
-
-		childLogger.Info().Interface("trace_id", trace_id).Interface("=========>>>>> transfer: ",transfer).Msg("<==========")
-
-		_, _, err := apiService.CallApi(ctx,
-												s.apiService[2].Url,
-												s.apiService[2].Method,
-												&s.apiService[2].Header_x_apigw_api_id,
-												nil,
-												&trace_id, 
-												transfer)
+	// Reserve the idempotency key inside the transaction
+	if idempotencyKey != nil {
+		_, err = s.workerRepository.ReserveIdempotencyKey(ctx, tx, idempotencyKey)
 		if err != nil {
 			return nil, err
 		}
-		credit.Obs =  "transaction send via circuit breaker !!!"
-		
-		spanCB.End()
-		return credit, nil
 	}
 
-	// Business rules
-	if credit.Type != "CREDIT" {
-		return nil, erro.ErrTransInvalid
+	// Get transaction UUID 
+	res_uuid, err := s.workerRepository.GetTransactionUUID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	if credit.Amount < 0 {
-		return nil, erro.ErrInvalidAmount
+	credit.TransactionID = res_uuid
+
+	// Add the credit (create account_statement)
+	res, err := s.workerRepository.AddCredit(ctx, tx, credit)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get the Account ID (PK) from Account-service
-	res_payload, statusCode, err := apiService.CallApi(ctx,
-														s.apiService[0].Url + "/" + credit.AccountID,
-														s.apiService[0].Method,
-														&s.apiService[0].Header_x_apigw_api_id,
-														nil,
-														&trace_id, 
-														nil)
+	// Add the outbox event in the same transaction, the relay will deliver it
+	// to (POST/AddFundBalanceAccount) go-account
+	payload, err := json.Marshal(res)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	outboxEvent := model.OutboxEvent{	AggregateID: res.ID,
+										EventType: "CREDIT-BALANCE",
+										Payload: payload,
+	}
+	_, err = s.workerRepository.AddOutboxEvent(ctx, tx, &outboxEvent)
 	if err != nil {
-		return nil, errorStatusCode(statusCode)
+		return nil, err
 	}
 
-	jsonString, err  := json.Marshal(res_payload)
+	// Store the response of the idempotency key
+	err = s.updateIdempotencyKey(ctx, tx, idempotencyKey, res)
+	if err != nil {
+		return nil, err
+	}
+	// Commit, the request fails when the commit fails
+	err = tx.Commit(ctx)
 	if err != nil {
 		return nil, errors.New(err.Error())
-    }
-	var account_parsed model.Account
-	json.Unmarshal(jsonString, &account_parsed)
+	}
 
-	// Business rule
-	credit.FkAccountID = account_parsed.ID
+	telemetry.SetCreditAttributes(span, res)
 
-	// Get transaction UUID 
-	res_uuid, err := s.workerRepository.GetTransactionUUID(ctx)
+	return res, nil
+}
+
+// About send the credit to go-fund-transfer when go-account is unavailable
+func (s *WorkerService) addCreditFallback(ctx context.Context, credit *model.AccountStatement) (*model.AccountStatement, error){
+	childLogger.Info().Str("func","addCreditFallback").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	spanCB := tracerProvider.Span(ctx, "service.AddCredit-CIRCUIT-BREAKER")
+	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
+	defer spanCB.End()
+	telemetry.SetCreditAttributes(spanCB, credit)
+	telemetry.SetDownstream(spanCB, "fund-transfer")
+
+	transfer := model.Transfer{}
+	transfer.Currency = credit.Currency
+	transfer.Amount = credit.Amount
+	transfer.Type = "CREDIT"
+	transfer.AccountFrom = credit
+
+	childLogger.Info().Interface("trace_id", trace_id).Interface("=========>>>>> transfer: ",transfer).Msg("<==========")
+
+	err := s.transferClient.CreditTransfer(ctx, &transfer)
 	if err != nil {
+		telemetry.RecordError(spanCB, err)
 		return nil, err
 	}
-	credit.TransactionID = res_uuid
+	credit.Obs =  "transaction send via circuit breaker !!!"
 
-	// Add the credit (create account_statement)
-	res, err := s.workerRepository.AddCredit(ctx, tx, credit)
+	return credit, nil
+}
+
+// About credit via go-fund-transfer when go-account is unavailable (circuit breaker open)
+// The idempotency key is reserved and committed before the call, no transaction (or row lock)
+// is held during it, and the response is stored after it in a new transaction
+func (s *WorkerService) addCreditCircuitOpen(ctx context.Context, credit *model.AccountStatement, idempotencyKey *model.IdempotencyKey) (*model.AccountStatement, error){
+	childLogger.Info().Str("func","addCreditCircuitOpen").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Only for a account already seen of the caller tenant, otherwise the tenant can not be checked
+	res_cached, ok := s.accountCache.get(credit.AccountID)
+	if !ok {
+		childLogger.Warn().Str("account_id", credit.AccountID).Msg("circuit breaker open and account unknown, credit refused")
+		return nil, erro.ErrServiceUnavailable
+	}
+	err := checkAccountTenant(res_cached, credit.TenantID)
 	if err != nil {
 		return nil, err
 	}
+	credit.FkAccountID = res_cached.ID
 
-	// Add (POST/AddFundBalanceAccount) the updat account statement 
-	_, statusCode, err = apiService.CallApi(ctx,
-											s.apiService[1].Url,
-											s.apiService[1].Method,
-											&s.apiService[1].Header_x_apigw_api_id,
-											nil,
-											&trace_id,
-											credit)
+	// Reserve the idempotency key, a concurrent replay gets a conflict while it is reserved
+	if idempotencyKey != nil {
+		err = s.withTx(ctx, func(tx pgx.Tx) error {
+			_, err := s.workerRepository.ReserveIdempotencyKey(ctx, tx, idempotencyKey)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res_fallback, err := s.addCreditFallback(ctx, credit)
 	if err != nil {
-		return nil, errorStatusCode(statusCode)
+		// release the reservation, the request can be retried with the same key
+		if idempotencyKey != nil {
+			errRelease := s.withTx(ctx, func(tx pgx.Tx) error {
+				_, err := s.workerRepository.DeleteIdempotencyKey(ctx, tx, idempotencyKey)
+				return err
+			})
+			if errRelease != nil {
+				childLogger.Error().Err(errRelease).Str("idempotency_key", idempotencyKey.Key).Msg("error release idempotency key")
+			}
+		}
+		return nil, err
 	}
 
-	return res, nil
+	// Store the response of the idempotency key
+	if idempotencyKey != nil {
+		err = s.withTx(ctx, func(tx pgx.Tx) error {
+			return s.updateIdempotencyKey(ctx, tx, idempotencyKey, res_fallback)
+		})
+		if err != nil {
+			// the credit is done, the key stays reserved until it expires (a replay gets a conflict)
+			childLogger.Error().Err(err).Str("idempotency_key", idempotencyKey.Key).Msg("error store idempotency key")
+		}
+	}
+
+	return res_fallback, nil
+}
+
+// About store the response of the idempotency key (when there is one)
+func (s *WorkerService) updateIdempotencyKey(ctx context.Context, tx pgx.Tx, idempotencyKey *model.IdempotencyKey, res *model.AccountStatement) error{
+	if idempotencyKey == nil {
+		return nil
+	}
+
+	response, err := json.Marshal(res)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	idempotencyKey.StatusCode = http.StatusOK
+	idempotencyKey.Response = response
+
+	_, err = s.workerRepository.UpdateIdempotencyKey(ctx, tx, idempotencyKey)
+	return err
+}
+
+// About encode the position of the last item of a page
+func encodeCursor(credit *model.AccountStatement) string {
+	cursor := credit.ChargeAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(credit.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor))
+}
+
+// About decode a cursor created by encodeCursor
+func decodeCursor(listFilter *model.ListFilter) error {
+	if listFilter.Cursor == nil || *listFilter.Cursor == "" {
+		return nil
+	}
+
+	cursor, err := base64.RawURLEncoding.DecodeString(*listFilter.Cursor)
+	if err != nil {
+		return erro.ErrInvalidParameter
+	}
+	charge_at, id, ok := strings.Cut(string(cursor), "|")
+	if !ok {
+		return erro.ErrInvalidParameter
+	}
+	cursorChargeAt, err := time.Parse(time.RFC3339Nano, charge_at)
+	if err != nil {
+		return erro.ErrInvalidParameter
+	}
+	cursorID, err := strconv.Atoi(id)
+	if err != nil {
+		return erro.ErrInvalidParameter
+	}
+
+	listFilter.CursorChargeAt = cursorChargeAt
+	listFilter.CursorID = cursorID
+
+	return nil
+}
+
+// About resolve the account_id (go-account) to the fk_account_id of the credits, the account must be of the caller tenant
+func (s *WorkerService) resolveAccount(ctx context.Context, credit *model.AccountStatement) error{
+	if credit.TenantID == "" {
+		return erro.ErrInvalidParameter
+	}
+
+	res_account, err := s.getAccount(ctx, credit.AccountID)
+	if err != nil {
+		return err
+	}
+
+	// Business rule
+	err = checkAccountTenant(res_account, credit.TenantID)
+	if err != nil {
+		return err
+	}
+	credit.FkAccountID = res_account.ID
+	credit.Type = "CREDIT"
+
+	return nil
 }
 
 // About list credit
-func (s *WorkerService) ListCredit(ctx context.Context, credit *model.AccountStatement) (*[]model.AccountStatement, error){
+func (s *WorkerService) ListCredit(ctx context.Context, credit *model.AccountStatement, listFilter *model.ListFilter) (*model.AccountStatementPage, error){
 	childLogger.Info().Str("func","ListCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("credit", credit).Send()
 
 	// Trace
 	span := tracerProvider.Span(ctx, "service.ListCredit")
-	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
 	defer span.End()
+	telemetry.SetCreditAttributes(span, credit)
 	
 	// Get the Account ID from Account-service
-	res_payload, statusCode, err := apiService.CallApi(ctx,
-														s.apiService[0].Url + "/" + credit.AccountID,
-														s.apiService[0].Method,
-														&s.apiService[0].Header_x_apigw_api_id,
-														nil,
-														&trace_id,
-														nil)
+	err := s.resolveAccount(ctx, credit)
 	if err != nil {
-		return nil, errorStatusCode(statusCode)
+		telemetry.RecordError(span, err)
+		return nil, err
 	}
 
-	jsonString, err  := json.Marshal(res_payload)
+	// Pagination
+	if listFilter.Limit <= 0 {
+		listFilter.Limit = s.listConfig.DefaultLimit
+	}
+	if listFilter.Limit > s.listConfig.MaxLimit {
+		listFilter.Limit = s.listConfig.MaxLimit
+	}
+	// a page has at least one item, the next cursor is the last item of the page
+	if listFilter.Limit < 1 {
+		listFilter.Limit = 1
+	}
+	err = decodeCursor(listFilter)
 	if err != nil {
-		return nil, errors.New(err.Error())
-    }
-	var account_parsed model.Account
-	json.Unmarshal(jsonString, &account_parsed)
+		telemetry.RecordError(span, err)
+		return nil, err
+	}
 
-	// Business rule
-	credit.FkAccountID = account_parsed.ID
-	credit.Type = "CREDIT"
+	// read one more item to know if there is a next page
+	page_limit := listFilter.Limit
+	listFilter.Limit = page_limit + 1
 
-	res, err := s.workerRepository.ListCredit(ctx, credit)
+	res, err := s.workerRepository.ListCredit(ctx, credit, listFilter)
 	if err != nil {
+		telemetry.RecordError(span, err)
 		return nil, err
 	}
-	return res, nil
+
+	res_page := model.AccountStatementPage{Data: *res}
+	if len(res_page.Data) > page_limit {
+		res_page.Data = res_page.Data[:page_limit]
+		next_cursor := encodeCursor(&res_page.Data[page_limit - 1])
+		res_page.NextCursor = &next_cursor
+	}
+
+	return &res_page, nil
 }
 
 // About list credit per date
-func (s *WorkerService) ListCreditPerDate(ctx context.Context, credit *model.AccountStatement) (*[]model.AccountStatement, error){
+func (s *WorkerService) ListCreditPerDate(ctx context.Context, credit *model.AccountStatement, listFilter *model.ListFilter) (*[]model.AccountStatement, error){
 	childLogger.Info().Str("func","ListCreditPerDate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("credit", credit).Send()
 
 	// Trace
 	span := tracerProvider.Span(ctx, "service.ListCreditPerDate")
-	trace_id := fmt.Sprintf("%v",ctx.Value("trace-request-id"))
 	defer span.End()
+	telemetry.SetCreditAttributes(span, credit)
+
+	// Business rule, a date_end without value is now
+	if listFilter.DateEnd.IsZero() {
+		listFilter.DateEnd = time.Now()
+	}
+	if listFilter.DateStart.After(listFilter.DateEnd) {
+		telemetry.RecordError(span, erro.ErrInvalidDateRange)
+		return nil, erro.ErrInvalidDateRange
+	}
+	if s.listConfig.MaxDateRange > 0 && listFilter.DateEnd.Sub(listFilter.DateStart) > time.Duration(s.listConfig.MaxDateRange) * 24 * time.Hour {
+		telemetry.RecordError(span, erro.ErrInvalidDateRange)
+		return nil, erro.ErrInvalidDateRange
+	}
+	// charged_at is a timestamptz, the range is compared as instants (in UTC). The day
+	// boundaries were already computed in the time zone of the request (tz, default UTC)
+	listFilter.DateStart = listFilter.DateStart.UTC()
+	listFilter.DateEnd = listFilter.DateEnd.UTC()
 	
 	// Get the Account ID from Account-service
-	res_payload, statusCode, err := apiService.CallApi(ctx,
-														s.apiService[0].Url + "/" + credit.AccountID,
-														s.apiService[0].Method,
-														&s.apiService[0].Header_x_apigw_api_id,
-														nil,
-														&trace_id,
-														nil)
+	err := s.resolveAccount(ctx, credit)
 	if err != nil {
-		return nil, errorStatusCode(statusCode)
+		telemetry.RecordError(span, err)
+		return nil, err
 	}
 
-	jsonString, err  := json.Marshal(res_payload)
+	res, err := s.workerRepository.ListCreditPerDate(ctx, credit, listFilter)
 	if err != nil {
-		return nil, errors.New(err.Error())
-    }
-	var account_parsed model.Account
-	json.Unmarshal(jsonString, &account_parsed)
+		telemetry.RecordError(span, err)
+		return nil, err
+	}
+	return res, nil
+}
+
+// About get a credit by id or transaction id
+func (s *WorkerService) GetCredit(ctx context.Context, credit *model.AccountStatement) (*model.AccountStatement, error){
+	childLogger.Info().Str("func","GetCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("credit", credit).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.GetCredit")
+	defer span.End()
+	telemetry.SetCreditAttributes(span, credit)
 
 	// Business rule
-	credit.FkAccountID = account_parsed.ID
-	credit.Type = "CREDIT"
+	if credit.TenantID == "" {
+		telemetry.RecordError(span, erro.ErrInvalidParameter)
+		return nil, erro.ErrInvalidParameter
+	}
+	if credit.ID == 0 && (credit.TransactionID == nil || *credit.TransactionID == "") {
+		telemetry.RecordError(span, erro.ErrNotFound)
+		return nil, erro.ErrNotFound
+	}
+
+	res, err := s.workerRepository.GetCredit(ctx, credit)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return nil, err
+	}
 
-	res, err := s.workerRepository.ListCreditPerDate(ctx, credit)
+	// Get the Account ID (string) back from Account-service (or the last known account)
+	res_account, err := s.getAccountByID(ctx, res.FkAccountID)
 	if err != nil {
+		telemetry.RecordError(span, err)
 		return nil, err
 	}
+
+	res.AccountID = res_account.AccountID
+
 	return res, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/core/model"
+)
+
+func TestAddCredit(t *testing.T) {
+	workerService, repository, _ := newTestService(t, &model.OutboxConfig{})
+	ctx := context.Background()
+
+	res, err := workerService.AddCredit(ctx, newCredit(t, "10.5", ""))
+	if err != nil {
+		t.Fatalf("add credit: %v", err)
+	}
+	if res.ID == 0 || res.TransactionID == nil || res.FkAccountID != 1 {
+		t.Fatalf("add credit: unexpected result %+v", res)
+	}
+	if res.Amount.String() != "10.50" {
+		t.Errorf("amount = %s, want 10.50 (currency scale)", res.Amount)
+	}
+
+	res_credit, err := repository.GetCredit(ctx, &model.AccountStatement{ID: res.ID, TenantID: testTenantID})
+	if err != nil {
+		t.Fatalf("get credit: %v", err)
+	}
+	if *res_credit.TransactionID != *res.TransactionID {
+		t.Errorf("transaction_id = %s, want %s", *res_credit.TransactionID, *res.TransactionID)
+	}
+
+	pending := repository.pendingOutboxEvents(t)
+	if len(pending) != 1 || pending[0].AggregateID != res.ID {
+		t.Errorf("pending outbox events = %+v, want one of the credit %d", pending, res.ID)
+	}
+}
+
+func TestAddCreditRollback(t *testing.T) {
+	workerService, repository, _ := newTestService(t, &model.OutboxConfig{})
+	ctx := context.Background()
+
+	// the outbox event is the last write of the transaction
+	repository.errAddOutboxEvent = errors.New("outbox unavailable")
+
+	_, err := workerService.AddCredit(ctx, newCredit(t, "10.00", "KEY-1"))
+	if !errors.Is(err, repository.errAddOutboxEvent) {
+		t.Fatalf("add credit: err = %v, want %v", err, repository.errAddOutboxEvent)
+	}
+
+	_, err = repository.GetCredit(ctx, &model.AccountStatement{ID: 1, TenantID: testTenantID})
+	if err != erro.ErrNotFound {
+		t.Errorf("get credit: err = %v, want %v (credit rolled back)", err, erro.ErrNotFound)
+	}
+	_, err = repository.GetIdempotencyKey(ctx, testTenantID, "KEY-1")
+	if err != erro.ErrNotFound {
+		t.Errorf("get idempotency key: err = %v, want %v (reservation rolled back)", err, erro.ErrNotFound)
+	}
+	if pending := repository.pendingOutboxEvents(t); len(pending) != 0 {
+		t.Errorf("pending outbox events = %d, want 0", len(pending))
+	}
+
+	// the same key works once the failure is gone
+	repository.errAddOutboxEvent = nil
+	_, err = workerService.AddCredit(ctx, newCredit(t, "10.00", "KEY-1"))
+	if err != nil {
+		t.Fatalf("add credit after rollback: %v", err)
+	}
+}
+
+func TestAddCreditIdempotentReplay(t *testing.T) {
+	workerService, repository, _ := newTestService(t, &model.OutboxConfig{})
+	ctx := context.Background()
+
+	res, err := workerService.AddCredit(ctx, newCredit(t, "10.5", "KEY-1"))
+	if err != nil {
+		t.Fatalf("add credit: %v", err)
+	}
+
+	// the same amount written with another scale is the same request
+	res_replay, err := workerService.AddCredit(ctx, newCredit(t, "10.50", "KEY-1"))
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if res_replay.ID != res.ID || *res_replay.TransactionID != *res.TransactionID {
+		t.Errorf("replay = %d/%s, want %d/%s", res_replay.ID, *res_replay.TransactionID, res.ID, *res.TransactionID)
+	}
+
+	_, err = repository.GetCredit(ctx, &model.AccountStatement{ID: res.ID + 1, TenantID: testTenantID})
+	if err != erro.ErrNotFound {
+		t.Errorf("get second credit: err = %v, want %v", err, erro.ErrNotFound)
+	}
+	if pending := repository.pendingOutboxEvents(t); len(pending) != 1 {
+		t.Errorf("pending outbox events = %d, want 1", len(pending))
+	}
+}
+
+func TestAddCreditIdempotencyConflict(t *testing.T) {
+	workerService, _, _ := newTestService(t, &model.OutboxConfig{})
+	ctx := context.Background()
+
+	_, err := workerService.AddCredit(ctx, newCredit(t, "10.00", "KEY-1"))
+	if err != nil {
+		t.Fatalf("add credit: %v", err)
+	}
+
+	_, err = workerService.AddCredit(ctx, newCredit(t, "20.00", "KEY-1"))
+	if err != erro.ErrIdempotencyConflict {
+		t.Errorf("same key with another amount: err = %v, want %v", err, erro.ErrIdempotencyConflict)
+	}
+}
+
+func TestGetCreditAccountID(t *testing.T) {
+	workerService, _, accountServer := newTestService(t, &model.OutboxConfig{})
+	ctx := context.Background()
+
+	res, err := workerService.AddCredit(ctx, newCredit(t, "10.00", ""))
+	if err != nil {
+		t.Fatalf("add credit: %v", err)
+	}
+
+	res_credit, err := workerService.GetCredit(ctx, &model.AccountStatement{TransactionID: res.TransactionID, TenantID: testTenantID})
+	if err != nil {
+		t.Fatalf("get credit: %v", err)
+	}
+	if res_credit.AccountID != "ACC-1" || res_credit.FkAccountID != 1 {
+		t.Errorf("account = %s/%d, want ACC-1/1", res_credit.AccountID, res_credit.FkAccountID)
+	}
+	// the account was read by the credit, go-account is not called again
+	if got := accountServer.Requests("account-get-id"); got != 0 {
+		t.Errorf("account-get-id requests = %d, want 0", got)
+	}
+}
+
+func TestAddCreditAmountRules(t *testing.T) {
+	tests := []struct {
+		amount	string
+		wantErr	error
+	}{
+		{"0", nil},
+		{"10.5", nil},
+		{"-1", erro.ErrInvalidAmount},
+		{"10.505", erro.ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			workerService, _, _ := newTestService(t, &model.OutboxConfig{})
+			ctx := context.Background()
+
+			// the same credit is valid or invalid on /add and /add/batch
+			_, err := workerService.AddCredit(ctx, newCredit(t, tt.amount, ""))
+			if err != tt.wantErr {
+				t.Errorf("add credit: err = %v, want %v", err, tt.wantErr)
+			}
+
+			res_batch, err := workerService.AddCreditBatch(ctx, []model.AccountStatement{*newCredit(t, tt.amount, "")}, false)
+			if err != nil {
+				t.Fatalf("add credit batch: %v", err)
+			}
+			item := res_batch.Items[0]
+			if (tt.wantErr == nil) != (item.Status == "OK") || (tt.wantErr != nil && item.Error != tt.wantErr.Error()) {
+				t.Errorf("batch item = %s %q, want err %v", item.Status, item.Error, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import(
+	"time"
+	"context"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/infra/telemetry"
+)
+
+// About stream the credits of an account to fn (ordered by charged_at asc).
+// Without a date_start it is the ListCredit filter, with it the ListCreditPerDate range
+func (s *WorkerService) ExportCredit(ctx context.Context, credit *model.AccountStatement, listFilter *model.ListFilter, fn func(*model.AccountStatement) error) error{
+	childLogger.Info().Str("func","ExportCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("credit", credit).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ExportCredit")
+	defer span.End()
+	telemetry.SetCreditAttributes(span, credit)
+
+	// Business rule, the same date range of the list per date
+	if !listFilter.DateStart.IsZero() {
+		if listFilter.DateEnd.IsZero() {
+			listFilter.DateEnd = time.Now()
+		}
+		if listFilter.DateStart.After(listFilter.DateEnd) {
+			telemetry.RecordError(span, erro.ErrInvalidDateRange)
+			return erro.ErrInvalidDateRange
+		}
+		if s.listConfig.MaxDateRange > 0 && listFilter.DateEnd.Sub(listFilter.DateStart) > time.Duration(s.listConfig.MaxDateRange) * 24 * time.Hour {
+			telemetry.RecordError(span, erro.ErrInvalidDateRange)
+			return erro.ErrInvalidDateRange
+		}
+		// charged_at is a timestamptz, the range is compared as instants (in UTC). The day
+		// boundaries were already computed in the time zone of the request (tz, default UTC)
+		listFilter.DateStart = listFilter.DateStart.UTC()
+		listFilter.DateEnd = listFilter.DateEnd.UTC()
+	} else if !listFilter.DateEnd.IsZero() {
+		telemetry.RecordError(span, erro.ErrInvalidDateRange)
+		return erro.ErrInvalidDateRange
+	}
+
+	// Get the Account ID from Account-service
+	err := s.resolveAccount(ctx, credit)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return err
+	}
+
+	err = s.workerRepository.StreamCredit(ctx, credit, listFilter, func(res *model.AccountStatement) error {
+		res.AccountID = credit.AccountID
+		return fn(res)
+	})
+	telemetry.RecordError(span, err)
+
+	return err
+}
@@ -0,0 +1,56 @@
+package service
+
+import(
+	"time"
+	"slices"
+	"context"
+
+	"github.com/go-credit/internal/core/model"
+)
+
+// About the readiness of the service, a bounded ping of the database and the breaker state of the endpoints.
+// The status is down when a critical component is down, degraded when any other component is failing
+func (s *WorkerService) HealthCheck(ctx context.Context) (*model.HealthReport){
+	childLogger.Info().Str("func","HealthCheck").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.HealthCheck")
+	defer span.End()
+
+	res_health := model.HealthReport{	Status: "up",
+										CheckedAt: time.Now(),
+	}
+
+	// the database, the ping waits a free connection of the pool until the timeout
+	database := model.DependencyStatus{	Name: "database",
+										Type: "database",
+										Status: "up",
+	}
+	ctxPing, cancel := context.WithTimeout(ctx, time.Duration(s.healthConfig.Timeout) * time.Millisecond)
+	defer cancel()
+
+	err := s.workerRepository.Ping(ctxPing)
+	database.LatencyMs = time.Since(res_health.CheckedAt).Milliseconds()
+	if err != nil {
+		childLogger.Error().Err(err).Msg("health check database down")
+		database.Status = "down"
+		database.Error = err.Error()
+	}
+
+	res_health.Components = append([]model.DependencyStatus{database}, s.endpointDependency()...)
+
+	for i := range res_health.Components {
+		component := &res_health.Components[i]
+		component.Critical = slices.Contains(s.healthConfig.Critical, component.Name)
+
+		switch {
+		case component.Status == "up":
+		case component.Critical && component.Status == "down":
+			res_health.Status = "down"
+		case res_health.Status == "up":
+			res_health.Status = "degraded"
+		}
+	}
+
+	return &res_health
+}
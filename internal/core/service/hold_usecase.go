@@ -0,0 +1,316 @@
+package service
+
+import(
+	"time"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/infra/telemetry"
+)
+
+// About reserve a pending credit, the go-account balance is not updated
+func (s *WorkerService) AddHold(ctx context.Context, hold *model.Hold) (*model.Hold, error){
+	childLogger.Info().Str("func","AddHold").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("hold", hold).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddHold")
+	telemetry.SetHoldAttributes(span, hold)
+
+	// Business rules
+	if hold.Amount.Sign() <= 0 {
+		telemetry.RecordError(span, erro.ErrInvalidAmount)
+		span.End()
+		return nil, erro.ErrInvalidAmount
+	}
+	amount, err := hold.Amount.ForCurrency(hold.Currency)
+	if err != nil {
+		telemetry.RecordError(span, erro.ErrInvalidAmount)
+		span.End()
+		return nil, erro.ErrInvalidAmount
+	}
+	hold.Amount = amount
+	if hold.TenantID == "" {
+		telemetry.RecordError(span, erro.ErrInvalidParameter)
+		span.End()
+		return nil, erro.ErrInvalidParameter
+	}
+
+	// Get the Account ID from Account-service
+	res_account, err := s.getAccount(ctx, hold.AccountID)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+	err = checkAccountTenant(res_account, hold.TenantID)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+	hold.FkAccountID = res_account.ID
+
+	// Get the database connection
+	tx, conn, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction, the rollback is a no-op after the commit
+	defer func() {
+		if err != nil {
+			telemetry.RecordError(span, err)
+		}
+		tx.Rollback(ctx)
+		s.workerRepository.ReleaseTx(conn)
+		span.End()
+	}()
+
+	// Get transaction UUID, the same transaction id goes to the credit when captured
+	res_uuid, err := s.workerRepository.GetTransactionUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hold.TransactionID = res_uuid
+	hold.ExpiresAt = time.Now().Add(time.Duration(s.holdConfig.TTL) * time.Second)
+
+	res, err := s.workerRepository.AddHold(ctx, tx, hold)
+	if err != nil {
+		return nil, err
+	}
+	// Commit, the request fails when the commit fails
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	telemetry.SetHoldAttributes(span, res)
+
+	return res, nil
+}
+
+// About post a pending hold as a credit
+func (s *WorkerService) CaptureHold(ctx context.Context, hold *model.Hold) (*model.Hold, error){
+	childLogger.Info().Str("func","CaptureHold").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("hold_id", hold.ID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.CaptureHold")
+	telemetry.SetHoldAttributes(span, hold)
+
+	// Business rule, only a hold of the caller tenant
+	if hold.TenantID == "" {
+		telemetry.RecordError(span, erro.ErrInvalidParameter)
+		span.End()
+		return nil, erro.ErrInvalidParameter
+	}
+
+	// Get the database connection
+	tx, conn, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction, the rollback is a no-op after the commit
+	defer func() {
+		if err != nil {
+			telemetry.RecordError(span, err)
+		}
+		tx.Rollback(ctx)
+		s.workerRepository.ReleaseTx(conn)
+		span.End()
+	}()
+
+	// Get (and lock) the hold
+	res_hold, err := s.workerRepository.GetHold(ctx, tx, hold)
+	if err != nil {
+		return nil, err
+	}
+
+	// Business rules
+	if res_hold.Status != "PENDING" {
+		err = erro.ErrHoldNotPending
+		return nil, err
+	}
+	if !res_hold.ExpiresAt.After(time.Now()) {
+		err = erro.ErrHoldExpired
+		return nil, err
+	}
+
+	// Add the credit (create account_statement)
+	credit := model.AccountStatement{	FkAccountID: res_hold.FkAccountID,
+										Type: "CREDIT",
+										Currency: res_hold.Currency,
+										Amount: res_hold.Amount,
+										TenantID: res_hold.TenantID,
+										TransactionID: res_hold.TransactionID,
+										Obs: res_hold.Obs,
+										Metadata: res_hold.Metadata,
+	}
+	res_credit, err := s.workerRepository.AddCredit(ctx, tx, &credit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the outbox event, the relay will update the balance in go-account
+	payload, err := json.Marshal(res_credit)
+	if err != nil {
+		err = errors.New(err.Error())
+		return nil, err
+	}
+	outboxEvent := model.OutboxEvent{	AggregateID: res_credit.ID,
+										EventType: "CREDIT-BALANCE",
+										Payload: payload,
+	}
+	_, err = s.workerRepository.AddOutboxEvent(ctx, tx, &outboxEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Close the hold
+	res_hold.Status = "CAPTURED"
+	res_hold.FkCreditID = &res_credit.ID
+	_, err = s.workerRepository.UpdateHold(ctx, tx, res_hold)
+	if err != nil {
+		return nil, err
+	}
+	res_hold.Credit = res_credit
+	// Commit, the request fails when the commit fails
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	telemetry.SetHoldAttributes(span, res_hold)
+
+	return res_hold, nil
+}
+
+// About release a pending hold
+func (s *WorkerService) VoidHold(ctx context.Context, hold *model.Hold) (*model.Hold, error){
+	childLogger.Info().Str("func","VoidHold").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("hold_id", hold.ID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.VoidHold")
+	telemetry.SetHoldAttributes(span, hold)
+
+	// Business rule, only a hold of the caller tenant
+	if hold.TenantID == "" {
+		telemetry.RecordError(span, erro.ErrInvalidParameter)
+		span.End()
+		return nil, erro.ErrInvalidParameter
+	}
+
+	// Get the database connection
+	tx, conn, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction, the rollback is a no-op after the commit
+	defer func() {
+		if err != nil {
+			telemetry.RecordError(span, err)
+		}
+		tx.Rollback(ctx)
+		s.workerRepository.ReleaseTx(conn)
+		span.End()
+	}()
+
+	// Get (and lock) the hold
+	res_hold, err := s.workerRepository.GetHold(ctx, tx, hold)
+	if err != nil {
+		return nil, err
+	}
+
+	// Business rule, an expired hold is already released
+	if res_hold.Status != "PENDING" {
+		err = erro.ErrHoldNotPending
+		return nil, err
+	}
+
+	res_hold.Status = "VOIDED"
+	_, err = s.workerRepository.UpdateHold(ctx, tx, res_hold)
+	if err != nil {
+		return nil, err
+	}
+	// Commit, the request fails when the commit fails
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	telemetry.SetHoldAttributes(span, res_hold)
+
+	return res_hold, nil
+}
+
+// About start the hold expiry loop, it stops when the context is canceled
+func (s *WorkerService) StartHoldExpiry(ctx context.Context) {
+	childLogger.Info().Str("func","StartHoldExpiry").Interface("holdConfig", s.holdConfig).Send()
+
+	ticker := time.NewTicker(time.Duration(s.holdConfig.ExpiryInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			childLogger.Info().Msg("stop hold expiry !!!")
+			return
+		case <-ticker.C:
+			count, err := s.ExpireHold(ctx)
+			if err != nil {
+				childLogger.Error().Err(err).Msg("error expire hold")
+			} else if count > 0 {
+				childLogger.Info().Int64("holds", count).Msg("holds expired")
+			}
+		}
+	}
+}
+
+// About expire the pending holds past the ttl
+func (s *WorkerService) ExpireHold(ctx context.Context) (int64, error){
+	childLogger.Debug().Str("func","ExpireHold").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ExpireHold")
+
+	// Get the database connection
+	tx, conn, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return 0, err
+	}
+
+	// Handle the transaction, the rollback is a no-op after the commit
+	defer func() {
+		if err != nil {
+			telemetry.RecordError(span, err)
+		}
+		tx.Rollback(ctx)
+		s.workerRepository.ReleaseTx(conn)
+		span.End()
+	}()
+
+	count, err := s.workerRepository.ExpireHold(ctx, tx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	// Commit, the request fails when the commit fails
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return count, nil
+}
@@ -0,0 +1,209 @@
+package service
+
+import(
+	"time"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/infra/telemetry"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// About start the outbox relay loop, it stops when the context is canceled
+func (s *WorkerService) StartOutboxRelay(ctx context.Context) {
+	childLogger.Info().Str("func","StartOutboxRelay").Interface("outboxConfig", s.outboxConfig).Send()
+
+	ticker := time.NewTicker(time.Duration(s.outboxConfig.PollInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			childLogger.Info().Msg("stop outbox relay !!!")
+			return
+		case <-ticker.C:
+			count, err := s.RelayOutbox(ctx)
+			if err != nil {
+				childLogger.Error().Err(err).Msg("error relay outbox")
+			} else if count > 0 {
+				childLogger.Info().Int("events", count).Msg("outbox events processed")
+			}
+		}
+	}
+}
+
+// About deliver a batch of pending outbox events
+// The events are claimed in a short transaction and delivered outside of it, each status is
+// persisted in its own transaction while the claim is still the one of this relay. The delivery
+// is at-least-once (a crash after the delivery, or a claim expired during a slow delivery,
+// redelivers the event), go-account must deduplicate by the transaction_id
+func (s *WorkerService) RelayOutbox(ctx context.Context) (int, error){
+	childLogger.Debug().Str("func","RelayOutbox").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.RelayOutbox")
+	defer span.End()
+
+	res_list, err := s.claimOutboxEvents(ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return 0, err
+	}
+
+	// a failed update does not undo the status of the other events
+	var errUpdate error
+	for i := range *res_list {
+		outboxEvent := &(*res_list)[i]
+
+		errDelivery := s.deliverOutboxEvent(ctx, outboxEvent)
+		outboxEvent.Attempts = outboxEvent.Attempts + 1
+		if errDelivery == nil {
+			outboxEvent.Status = "DELIVERED"
+			outboxEvent.LastError = nil
+		} else {
+			childLogger.Error().Err(errDelivery).Int("outbox_id", outboxEvent.ID).Int("attempts", outboxEvent.Attempts).Msg("error deliver outbox event")
+
+			last_error := errDelivery.Error()
+			outboxEvent.LastError = &last_error
+			if outboxEvent.Attempts >= s.outboxConfig.MaxAttempts {
+				outboxEvent.Status = "FAILED"
+			} else {
+				outboxEvent.Status = "PENDING"
+				outboxEvent.NextAttemptAt = time.Now().Add(s.outboxBackoff(outboxEvent.Attempts))
+			}
+		}
+
+		err = s.updateOutboxEvent(ctx, outboxEvent)
+		if errors.Is(err, erro.ErrOutboxClaimLost) {
+			// the claim expired during the delivery, the status is the one of the other relay
+			childLogger.Warn().Int("outbox_id", outboxEvent.ID).Msg("outbox event claimed by another relay, status discarded")
+			continue
+		}
+		if err != nil {
+			childLogger.Error().Err(err).Int("outbox_id", outboxEvent.ID).Msg("error update outbox event")
+			errUpdate = err
+		}
+	}
+
+	if errUpdate != nil {
+		telemetry.RecordError(span, errUpdate)
+		return len(*res_list), errUpdate
+	}
+
+	return len(*res_list), nil
+}
+
+// About claim the pending outbox events with a new claim token, the next attempt is moved by the
+// claim timeout so another relay does not take them while they are delivered
+func (s *WorkerService) claimOutboxEvents(ctx context.Context) (*[]model.OutboxEvent, error){
+	childLogger.Debug().Str("func","claimOutboxEvents").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.claimOutboxEvents")
+
+	// Get the database connection
+	tx, conn, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction, the rollback is a no-op after the commit
+	defer func() {
+		if err != nil {
+			telemetry.RecordError(span, err)
+		}
+		tx.Rollback(ctx)
+		s.workerRepository.ReleaseTx(conn)
+		span.End()
+	}()
+
+	// the events stay locked (skip locked) until the commit
+	res_list, err := s.workerRepository.ListPendingOutboxEvent(ctx, tx, s.outboxConfig.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	claim_token := uuid.New().String()
+	claimed_until := time.Now().Add(time.Duration(s.outboxConfig.ClaimTimeout) * time.Second)
+	for i := range *res_list {
+		(*res_list)[i].NextAttemptAt = claimed_until
+		(*res_list)[i].ClaimToken = &claim_token
+
+		_, err = s.workerRepository.ClaimOutboxEvent(ctx, tx, &(*res_list)[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Commit, the request fails when the commit fails
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return res_list, nil
+}
+
+// About persist the delivery status of a outbox event in its own transaction, no row updated means
+// the claim was lost (ErrOutboxClaimLost)
+func (s *WorkerService) updateOutboxEvent(ctx context.Context, outboxEvent *model.OutboxEvent) error{
+	childLogger.Debug().Str("func","updateOutboxEvent").Int("outbox_id", outboxEvent.ID).Send()
+
+	return s.withTx(ctx, func(tx pgx.Tx) error {
+		row, err := s.workerRepository.UpdateOutboxEvent(ctx, tx, outboxEvent)
+		if err != nil {
+			return err
+		}
+		if row == 0 {
+			return erro.ErrOutboxClaimLost
+		}
+		return nil
+	})
+}
+
+// About deliver a single outbox event to the downstream service
+func (s *WorkerService) deliverOutboxEvent(ctx context.Context, outboxEvent *model.OutboxEvent) error{
+	childLogger.Debug().Str("func","deliverOutboxEvent").Int("outbox_id", outboxEvent.ID).Send()
+
+	switch outboxEvent.EventType {
+	case "CREDIT-BALANCE":
+		credit := model.AccountStatement{}
+		err := json.Unmarshal(outboxEvent.Payload, &credit)
+		if err != nil {
+			return errors.New(err.Error())
+		}
+
+		// the transaction_id (in the body and as request id) is the idempotency key, go-account
+		// must discard a redelivery of the same transaction_id
+		trace_id := ""
+		if credit.TransactionID != nil {
+			trace_id = *credit.TransactionID
+		}
+
+		// Add (POST/AddFundBalanceAccount) the updat account statement
+		return s.accountClient.AddAccountBalance(ctx, &credit, trace_id)
+	default:
+		return errors.New("outbox event type not supported: " + outboxEvent.EventType)
+	}
+}
+
+// About the exponential backoff for the next attempt
+func (s *WorkerService) outboxBackoff(attempts int) time.Duration {
+	backoff := time.Duration(s.outboxConfig.BackoffBase) * time.Second
+	max_backoff := time.Duration(s.outboxConfig.BackoffMax) * time.Second
+
+	for i := 1; i < attempts; i++ {
+		backoff = backoff * 2
+		if backoff >= max_backoff {
+			return max_backoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,173 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/adapter/client/fake"
+)
+
+func newOutboxConfig(maxAttempts int) *model.OutboxConfig {
+	return &model.OutboxConfig{	PollInterval: 1,
+								BatchSize: 10,
+								MaxAttempts: maxAttempts,
+								BackoffBase: 10,
+								BackoffMax: 300,
+								ClaimTimeout: 60,
+	}
+}
+
+func TestRelayOutboxDelivered(t *testing.T) {
+	workerService, repository, accountServer := newTestService(t, newOutboxConfig(3))
+	ctx := context.Background()
+
+	res, err := workerService.AddCredit(ctx, newCredit(t, "10.00", ""))
+	if err != nil {
+		t.Fatalf("add credit: %v", err)
+	}
+
+	count, err := workerService.RelayOutbox(ctx)
+	if err != nil || count != 1 {
+		t.Fatalf("relay: count = %d, err = %v, want 1 event", count, err)
+	}
+
+	balances := accountServer.Balances()
+	if len(balances) != 1 || *balances[0].TransactionID != *res.TransactionID {
+		t.Fatalf("balances = %+v, want the credit %s", balances, *res.TransactionID)
+	}
+
+	if len(repository.outboxUpdates) != 1 {
+		t.Fatalf("outbox updates = %d, want 1 event", len(repository.outboxUpdates))
+	}
+	for _, outboxEvent := range repository.outboxUpdates {
+		if outboxEvent.Status != "DELIVERED" || outboxEvent.Attempts != 1 || outboxEvent.LastError != nil {
+			t.Errorf("outbox event = %s/%d, want DELIVERED after 1 attempt", outboxEvent.Status, outboxEvent.Attempts)
+		}
+	}
+
+	count, err = workerService.RelayOutbox(ctx)
+	if err != nil || count != 0 {
+		t.Errorf("second relay: count = %d, err = %v, want nothing to deliver", count, err)
+	}
+}
+
+func TestRelayOutboxRetryBackoff(t *testing.T) {
+	workerService, repository, accountServer := newTestService(t, newOutboxConfig(3))
+	ctx := context.Background()
+
+	_, err := workerService.AddCredit(ctx, newCredit(t, "10.00", ""))
+	if err != nil {
+		t.Fatalf("add credit: %v", err)
+	}
+
+	accountServer.Fail("account-balance", http.StatusInternalServerError, 1)
+	start := time.Now()
+
+	count, err := workerService.RelayOutbox(ctx)
+	if err != nil || count != 1 {
+		t.Fatalf("relay: count = %d, err = %v, want 1 event", count, err)
+	}
+
+	for _, outboxEvent := range repository.outboxUpdates {
+		if outboxEvent.Status != "PENDING" || outboxEvent.Attempts != 1 || outboxEvent.LastError == nil {
+			t.Errorf("outbox event = %s/%d, want PENDING with the error after 1 attempt", outboxEvent.Status, outboxEvent.Attempts)
+		}
+		// first retry after OUTBOX_BACKOFF_BASE seconds
+		backoff := outboxEvent.NextAttemptAt.Sub(start)
+		if backoff < 10 * time.Second || backoff > 11 * time.Second {
+			t.Errorf("next attempt in %s, want 10s", backoff)
+		}
+	}
+
+	// not due before the backoff
+	count, err = workerService.RelayOutbox(ctx)
+	if err != nil || count != 0 {
+		t.Errorf("relay before the backoff: count = %d, err = %v, want nothing to deliver", count, err)
+	}
+	if len(accountServer.Balances()) != 0 {
+		t.Errorf("balances = %d, want 0", len(accountServer.Balances()))
+	}
+}
+
+func TestRelayOutboxFailed(t *testing.T) {
+	workerService, repository, accountServer := newTestService(t, newOutboxConfig(1))
+	ctx := context.Background()
+
+	_, err := workerService.AddCredit(ctx, newCredit(t, "10.00", ""))
+	if err != nil {
+		t.Fatalf("add credit: %v", err)
+	}
+
+	accountServer.Fail("account-balance", http.StatusInternalServerError, 1)
+
+	count, err := workerService.RelayOutbox(ctx)
+	if err != nil || count != 1 {
+		t.Fatalf("relay: count = %d, err = %v, want 1 event", count, err)
+	}
+
+	for _, outboxEvent := range repository.outboxUpdates {
+		if outboxEvent.Status != "FAILED" || outboxEvent.Attempts != 1 || outboxEvent.LastError == nil {
+			t.Errorf("outbox event = %s/%d, want FAILED after OUTBOX_MAX_ATTEMPTS", outboxEvent.Status, outboxEvent.Attempts)
+		}
+	}
+	if pending := repository.pendingOutboxEvents(t); len(pending) != 0 {
+		t.Errorf("pending outbox events = %d, want 0", len(pending))
+	}
+}
+
+func TestRelayOutboxClaimLost(t *testing.T) {
+	// the claim expires at once, a second relay claims the event during the slow delivery of the first
+	outboxConfig := newOutboxConfig(3)
+	outboxConfig.ClaimTimeout = 0
+	workerService, repository, accountServer := newTestService(t, outboxConfig)
+	ctx := context.Background()
+
+	res, err := workerService.AddCredit(ctx, newCredit(t, "10.00", ""))
+	if err != nil {
+		t.Fatalf("add credit: %v", err)
+	}
+
+	accountServer.Script("account-balance", fake.Response{Latency: 300 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	counts := make([]int, 2)
+	errs := make([]error, 2)
+	for i := range counts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			counts[i], errs[i] = workerService.RelayOutbox(ctx)
+		}(i)
+		time.Sleep(100 * time.Millisecond)
+	}
+	wg.Wait()
+
+	for i := range counts {
+		if errs[i] != nil || counts[i] != 1 {
+			t.Fatalf("relay %d: count = %d, err = %v, want 1 event", i, counts[i], errs[i])
+		}
+	}
+
+	// the status of the first relay is discarded, the one of the second is kept
+	if len(repository.outboxUpdates) != 1 {
+		t.Fatalf("outbox updates = %d, want 1", len(repository.outboxUpdates))
+	}
+	for _, outboxEvent := range repository.outboxUpdates {
+		if outboxEvent.Status != "DELIVERED" {
+			t.Errorf("outbox event = %s, want DELIVERED", outboxEvent.Status)
+		}
+	}
+
+	// delivered twice, applied once by go-account (X-Request-Id)
+	if got := accountServer.Requests("account-balance"); got != 2 {
+		t.Errorf("account-balance requests = %d, want 2", got)
+	}
+	balances := accountServer.Balances()
+	if len(balances) != 1 || *balances[0].TransactionID != *res.TransactionID {
+		t.Errorf("balances = %+v, want the credit %s once", balances, *res.TransactionID)
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import(
+	"time"
+	"context"
+
+	"github.com/go-credit/internal/core/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// the persistence used by the worker service
+// database.WorkerRepository is the postgres implementation, memory.WorkerRepository the in-memory one
+type CreditRepository interface {
+	// transaction handling, every ReleaseTx follows a Commit or Rollback of the tx
+	StartTx(ctx context.Context) (pgx.Tx, *pgxpool.Conn, error)
+	ReleaseTx(conn *pgxpool.Conn)
+	Ping(ctx context.Context) error
+
+	// credit, every query is scoped by the tenant of the credit
+	AddCredit(ctx context.Context, tx pgx.Tx, credit *model.AccountStatement) (*model.AccountStatement, error)
+	ListCredit(ctx context.Context, credit *model.AccountStatement, listFilter *model.ListFilter) (*[]model.AccountStatement, error)
+	ListCreditPerDate(ctx context.Context, credit *model.AccountStatement, listFilter *model.ListFilter) (*[]model.AccountStatement, error)
+	GetCredit(ctx context.Context, credit *model.AccountStatement) (*model.AccountStatement, error)
+	GetCreditByTransactionID(ctx context.Context, tx pgx.Tx, credit *model.AccountStatement) (*model.AccountStatement, error)
+	GetReversedAmount(ctx context.Context, tx pgx.Tx, credit *model.AccountStatement) (model.Money, error)
+	GetTransactionUUID(ctx context.Context) (*string, error)
+	AddCreditBatch(ctx context.Context, tx pgx.Tx, credits []model.AccountStatement) ([]model.AccountStatement, error)
+	StreamCredit(ctx context.Context, credit *model.AccountStatement, listFilter *model.ListFilter, fn func(*model.AccountStatement) error) error
+	SummaryCredit(ctx context.Context, credit *model.AccountStatement, summaryFilter *model.SummaryFilter) (*[]model.CreditSummaryGroup, error)
+
+	// idempotency
+	GetIdempotencyKey(ctx context.Context, tenantID string, key string) (*model.IdempotencyKey, error)
+	ReserveIdempotencyKey(ctx context.Context, tx pgx.Tx, idempotencyKey *model.IdempotencyKey) (*model.IdempotencyKey, error)
+	UpdateIdempotencyKey(ctx context.Context, tx pgx.Tx, idempotencyKey *model.IdempotencyKey) (int64, error)
+	DeleteIdempotencyKey(ctx context.Context, tx pgx.Tx, idempotencyKey *model.IdempotencyKey) (int64, error)
+
+	// hold
+	AddHold(ctx context.Context, tx pgx.Tx, hold *model.Hold) (*model.Hold, error)
+	GetHold(ctx context.Context, tx pgx.Tx, hold *model.Hold) (*model.Hold, error)
+	UpdateHold(ctx context.Context, tx pgx.Tx, hold *model.Hold) (int64, error)
+	ExpireHold(ctx context.Context, tx pgx.Tx, expiresAt time.Time) (int64, error)
+
+	// outbox
+	AddOutboxEvent(ctx context.Context, tx pgx.Tx, outboxEvent *model.OutboxEvent) (*model.OutboxEvent, error)
+	AddOutboxEventBatch(ctx context.Context, tx pgx.Tx, outboxEvents []model.OutboxEvent) ([]model.OutboxEvent, error)
+	ListPendingOutboxEvent(ctx context.Context, tx pgx.Tx, limit int) (*[]model.OutboxEvent, error)
+	// the claim token of the relay, a update without the current token affects no row
+	ClaimOutboxEvent(ctx context.Context, tx pgx.Tx, outboxEvent *model.OutboxEvent) (int64, error)
+	UpdateOutboxEvent(ctx context.Context, tx pgx.Tx, outboxEvent *model.OutboxEvent) (int64, error)
+}
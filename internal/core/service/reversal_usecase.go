@@ -0,0 +1,175 @@
+package service
+
+import(
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/infra/telemetry"
+)
+
+// About reverse (total or partial) a credit
+func (s *WorkerService) AddReversal(ctx context.Context, reversal *model.AccountStatement) (*model.AccountStatement, error){
+	childLogger.Info().Str("func","AddReversal").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("reversal", reversal).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddReversal")
+	telemetry.SetCreditAttributes(span, reversal)
+
+	// Business rules
+	if reversal.ReversalOf == nil || *reversal.ReversalOf == "" {
+		telemetry.RecordError(span, erro.ErrTransInvalid)
+		span.End()
+		return nil, erro.ErrTransInvalid
+	}
+	if reversal.Amount.IsNegative() {
+		telemetry.RecordError(span, erro.ErrInvalidAmount)
+		span.End()
+		return nil, erro.ErrInvalidAmount
+	}
+	if reversal.TenantID == "" {
+		telemetry.RecordError(span, erro.ErrInvalidParameter)
+		span.End()
+		return nil, erro.ErrInvalidParameter
+	}
+
+	// Get the Account ID (PK) from Account-service
+	res_account, err := s.getAccount(ctx, reversal.AccountID)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+	err = checkAccountTenant(res_account, reversal.TenantID)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	// Get the database connection
+	tx, conn, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction, the rollback is a no-op after the commit
+	defer func() {
+		if err != nil {
+			telemetry.RecordError(span, err)
+		}
+		tx.Rollback(ctx)
+		s.workerRepository.ReleaseTx(conn)
+		span.End()
+	}()
+
+	// Get (and lock) the original credit
+	original := model.AccountStatement{}
+	original.TransactionID = reversal.ReversalOf
+	original.Type = "CREDIT"
+	original.TenantID = reversal.TenantID
+
+	res_original, err := s.workerRepository.GetCreditByTransactionID(ctx, tx, &original)
+	if err != nil {
+		return nil, err
+	}
+	if res_original.FkAccountID != res_account.ID {
+		err = erro.ErrNotFound
+		return nil, err
+	}
+	// the amount in the precision of the currency of the original credit
+	reversal.Amount, err = reversal.Amount.ForCurrency(res_original.Currency)
+	if err != nil {
+		err = erro.ErrInvalidAmount
+		return nil, err
+	}
+
+	// Check the idempotency key with the original credit locked, a retry waits the first
+	// request and returns its result instead of a second reversal
+	reversal.Type = "CREDIT-REVERSAL"
+	idempotencyKey, res_replay, err := s.checkIdempotencyKey(ctx, reversal)
+	if err != nil {
+		return nil, err
+	}
+	if res_replay != nil {
+		return res_replay, nil
+	}
+	if idempotencyKey != nil {
+		_, err = s.workerRepository.ReserveIdempotencyKey(ctx, tx, idempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Business rule, refuse a reversal above the amount not yet reversed
+	reversed_amount, err := s.workerRepository.GetReversedAmount(ctx, tx, res_original)
+	if err != nil {
+		return nil, err
+	}
+	available_amount, err := res_original.Amount.Sub(reversed_amount)
+	if err != nil {
+		return nil, err
+	}
+	if available_amount.Sign() <= 0 {
+		err = erro.ErrAlreadyReversed
+		return nil, err
+	}
+	// a reversal without amount reverses all the available amount
+	if reversal.Amount.IsZero() {
+		reversal.Amount = available_amount
+	}
+	if reversal.Amount.Cmp(available_amount) > 0 {
+		err = erro.ErrInvalidAmount
+		return nil, err
+	}
+
+	// Get transaction UUID
+	res_uuid, err := s.workerRepository.GetTransactionUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare the compensating entry
+	reversal.FkAccountID = res_original.FkAccountID
+	reversal.Currency = res_original.Currency
+	reversal.TransactionID = res_uuid
+	reversal.Amount = reversal.Amount.Neg()
+
+	res, err := s.workerRepository.AddCredit(ctx, tx, reversal)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the outbox event, the relay will debit the balance in go-account
+	payload, err := json.Marshal(res)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	outboxEvent := model.OutboxEvent{	AggregateID: res.ID,
+										EventType: "CREDIT-BALANCE",
+										Payload: payload,
+	}
+	_, err = s.workerRepository.AddOutboxEvent(ctx, tx, &outboxEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the response of the idempotency key
+	err = s.updateIdempotencyKey(ctx, tx, idempotencyKey, res)
+	if err != nil {
+		return nil, err
+	}
+	// Commit, the request fails when the commit fails
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	telemetry.SetCreditAttributes(span, res)
+
+	return res, nil
+}
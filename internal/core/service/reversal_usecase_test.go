@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/core/model"
+)
+
+// About a reversal of a credit of ACC-1, without idempotency key when requestID is empty
+func newReversal(t *testing.T, transactionID string, amount string, requestID string) *model.AccountStatement {
+	t.Helper()
+
+	money, err := model.ParseMoney(amount)
+	if err != nil {
+		t.Fatalf("parse money %q: %v", amount, err)
+	}
+
+	reversal := &model.AccountStatement{	AccountID: "ACC-1",
+											Amount: money,
+											ReversalOf: &transactionID,
+											TenantID: testTenantID,
+	}
+	if requestID != "" {
+		reversal.RequestID = &requestID
+	}
+	return reversal
+}
+
+func TestAddReversalIdempotentReplay(t *testing.T) {
+	workerService, repository, _ := newTestService(t, &model.OutboxConfig{})
+	ctx := context.Background()
+
+	res_credit, err := workerService.AddCredit(ctx, newCredit(t, "10.00", ""))
+	if err != nil {
+		t.Fatalf("add credit: %v", err)
+	}
+
+	res, err := workerService.AddReversal(ctx, newReversal(t, *res_credit.TransactionID, "4", "REV-1"))
+	if err != nil {
+		t.Fatalf("add reversal: %v", err)
+	}
+	if res.Amount.String() != "-4.00" || res.Type != "CREDIT-REVERSAL" {
+		t.Fatalf("reversal = %s %s, want CREDIT-REVERSAL -4.00", res.Type, res.Amount)
+	}
+
+	// a retry (the amount with another scale) returns the first reversal
+	res_replay, err := workerService.AddReversal(ctx, newReversal(t, *res_credit.TransactionID, "4.00", "REV-1"))
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if res_replay.ID != res.ID || *res_replay.TransactionID != *res.TransactionID {
+		t.Errorf("replay = %d/%s, want %d/%s", res_replay.ID, *res_replay.TransactionID, res.ID, *res.TransactionID)
+	}
+
+	// the same key with another amount
+	_, err = workerService.AddReversal(ctx, newReversal(t, *res_credit.TransactionID, "5", "REV-1"))
+	if err != erro.ErrIdempotencyConflict {
+		t.Errorf("same key with another amount: err = %v, want %v", err, erro.ErrIdempotencyConflict)
+	}
+
+	// only the first reversal was debited, 6.00 is still available
+	_, err = workerService.AddReversal(ctx, newReversal(t, *res_credit.TransactionID, "6.01", ""))
+	if err != erro.ErrInvalidAmount {
+		t.Errorf("reversal above the available amount: err = %v, want %v", err, erro.ErrInvalidAmount)
+	}
+	if pending := repository.pendingOutboxEvents(t); len(pending) != 2 {
+		t.Errorf("pending outbox events = %d, want 2 (credit and one reversal)", len(pending))
+	}
+}
@@ -1,25 +1,82 @@
 package service
 
 import(
+	"context"
+	"errors"
+
 	"github.com/go-credit/internal/core/model"
-	"github.com/go-credit/internal/adapter/database"
+	"github.com/go-credit/internal/infra/circuitbreaker"
 	"github.com/rs/zerolog/log"
+
+	"github.com/jackc/pgx/v5"
 )
 
 var childLogger = log.With().Str("component","go-credit").Str("package","internal.core.service").Logger()
 
 type WorkerService struct {
-	workerRepository *database.WorkerRepository
-	apiService		[]model.ApiService
+	workerRepository CreditRepository
+	accountClient	AccountClient
+	transferClient	TransferClient
+	idempotencyConfig	*model.IdempotencyConfig
+	outboxConfig	*model.OutboxConfig
+	circuitBreakers	*circuitbreaker.CircuitBreakers
+	listConfig		*model.ListConfig
+	holdConfig		*model.HoldConfig
+	batchConfig		*model.BatchConfig
+	healthConfig	*model.HealthConfig
+	accountCache	*accountCache
 }
 
 // About create a ner worker service
-func NewWorkerService(	workerRepository *database.WorkerRepository,
-						apiService		[]model.ApiService) *WorkerService{
+func NewWorkerService(	workerRepository CreditRepository,
+						accountClient	AccountClient,
+						transferClient	TransferClient,
+						idempotencyConfig	*model.IdempotencyConfig,
+						outboxConfig	*model.OutboxConfig,
+						circuitBreakers	*circuitbreaker.CircuitBreakers,
+						listConfig		*model.ListConfig,
+						holdConfig		*model.HoldConfig,
+						batchConfig		*model.BatchConfig,
+						healthConfig	*model.HealthConfig) *WorkerService{
 	childLogger.Info().Str("func","NewWorkerService").Send()
 
 	return &WorkerService{
 		workerRepository: workerRepository,
-		apiService: apiService,
+		accountClient: accountClient,
+		transferClient: transferClient,
+		idempotencyConfig: idempotencyConfig,
+		outboxConfig: outboxConfig,
+		circuitBreakers: circuitBreakers,
+		listConfig: listConfig,
+		holdConfig: holdConfig,
+		batchConfig: batchConfig,
+		healthConfig: healthConfig,
+		accountCache: newAccountCache(),
+	}
+}
+
+// About run fn in its own short transaction, committed when fn succeeds
+func (s *WorkerService) withTx(ctx context.Context, fn func(tx pgx.Tx) error) (err error){
+	tx, conn, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		return err
 	}
+
+	// Handle the transaction, the rollback is a no-op after the commit
+	defer func() {
+		tx.Rollback(ctx)
+		s.workerRepository.ReleaseTx(conn)
+	}()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/service"
+	"github.com/go-credit/internal/adapter/client"
+	"github.com/go-credit/internal/adapter/client/fake"
+	"github.com/go-credit/internal/adapter/memory"
+	"github.com/go-credit/internal/infra/circuitbreaker"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const testTenantID = "TENANT-1"
+
+// memory repository with an injected failure and the last update of each outbox event
+type testRepository struct {
+	*memory.WorkerRepository
+	errAddOutboxEvent	error
+	outboxUpdates		map[int]model.OutboxEvent
+}
+
+func (r *testRepository) AddOutboxEvent(ctx context.Context, tx pgx.Tx, outboxEvent *model.OutboxEvent) (*model.OutboxEvent, error){
+	if r.errAddOutboxEvent != nil {
+		return nil, r.errAddOutboxEvent
+	}
+	return r.WorkerRepository.AddOutboxEvent(ctx, tx, outboxEvent)
+}
+
+func (r *testRepository) UpdateOutboxEvent(ctx context.Context, tx pgx.Tx, outboxEvent *model.OutboxEvent) (int64, error){
+	row, err := r.WorkerRepository.UpdateOutboxEvent(ctx, tx, outboxEvent)
+	if err == nil && row == 1 {
+		r.outboxUpdates[outboxEvent.ID] = *outboxEvent
+	}
+	return row, err
+}
+
+// About the pending outbox events, read in a transaction rolled back
+func (r *testRepository) pendingOutboxEvents(t *testing.T) []model.OutboxEvent {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, _, err := r.StartTx(ctx)
+	if err != nil {
+		t.Fatalf("start tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	res_list, err := r.ListPendingOutboxEvent(ctx, tx, 100)
+	if err != nil {
+		t.Fatalf("list pending outbox events: %v", err)
+	}
+	return *res_list
+}
+
+// About a worker service on the memory repository and a fake go-account with the account ACC-1
+func newTestService(t *testing.T, outboxConfig *model.OutboxConfig) (*service.WorkerService, *testRepository, *fake.AccountServer) {
+	t.Helper()
+
+	accountServer := fake.NewAccountServer()
+	t.Cleanup(accountServer.Close)
+	accountServer.AddAccount(model.Account{ID: 1, AccountID: "ACC-1", TenantID: testTenantID})
+
+	endpoints := accountServer.Endpoints()
+	circuitBreakerConfig := []model.CircuitBreakerConfig{}
+	for name := range endpoints {
+		circuitBreakerConfig = append(circuitBreakerConfig, model.CircuitBreakerConfig{	Name: name,
+																						MaxRequests: 1,
+																						Interval: 60,
+																						Timeout: 60,
+																						MaxFailures: 100,
+		})
+	}
+	circuitBreakers := circuitbreaker.NewCircuitBreakers(circuitBreakerConfig)
+	restClient := client.NewRestClient(endpoints, circuitBreakers)
+
+	repository := &testRepository{	WorkerRepository: memory.NewWorkerRepository(),
+									outboxUpdates: make(map[int]model.OutboxEvent),
+	}
+
+	workerService := service.NewWorkerService(repository,
+												restClient,
+												restClient,
+												&model.IdempotencyConfig{ExpirationWindow: 60},
+												outboxConfig,
+												circuitBreakers,
+												&model.ListConfig{DefaultLimit: 10, MaxLimit: 100},
+												&model.HoldConfig{},
+												&model.BatchConfig{MaxItems: 10},
+												&model.HealthConfig{Timeout: 100})
+
+	return workerService, repository, accountServer
+}
+
+// About a BRL credit of ACC-1, without idempotency key when requestID is empty
+func newCredit(t *testing.T, amount string, requestID string) *model.AccountStatement {
+	t.Helper()
+
+	money, err := model.ParseMoney(amount)
+	if err != nil {
+		t.Fatalf("parse money %q: %v", amount, err)
+	}
+
+	credit := &model.AccountStatement{	AccountID: "ACC-1",
+										Type: "CREDIT",
+										Currency: "BRL",
+										Amount: money,
+										TenantID: testTenantID,
+	}
+	if requestID != "" {
+		credit.RequestID = &requestID
+	}
+	return credit
+}
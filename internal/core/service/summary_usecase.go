@@ -0,0 +1,75 @@
+package service
+
+import(
+	"time"
+	"context"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+	"github.com/go-credit/internal/infra/telemetry"
+)
+
+// About the totals (count, sum, min, max, avg) of the credits of an account
+func (s *WorkerService) SummaryCredit(ctx context.Context, credit *model.AccountStatement, summaryFilter *model.SummaryFilter) (*model.CreditSummary, error){
+	childLogger.Info().Str("func","SummaryCredit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("credit", credit).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.SummaryCredit")
+	defer span.End()
+	telemetry.SetCreditAttributes(span, credit)
+
+	// Business rule, the totals are always per currency
+	if summaryFilter.GroupBy == "" {
+		summaryFilter.GroupBy = "currency"
+	}
+	switch summaryFilter.GroupBy {
+	case "day", "week", "month", "currency":
+	default:
+		telemetry.RecordError(span, erro.ErrInvalidGroupBy)
+		return nil, erro.ErrInvalidGroupBy
+	}
+	if summaryFilter.Location == nil {
+		summaryFilter.Location = time.UTC
+	}
+	if !summaryFilter.DateStart.IsZero() && !summaryFilter.DateEnd.IsZero() && summaryFilter.DateStart.After(summaryFilter.DateEnd) {
+		telemetry.RecordError(span, erro.ErrInvalidDateRange)
+		return nil, erro.ErrInvalidDateRange
+	}
+
+	// Get the Account ID from Account-service
+	err := s.resolveAccount(ctx, credit)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return nil, err
+	}
+
+	res, err := s.workerRepository.SummaryCredit(ctx, credit, summaryFilter)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return nil, err
+	}
+
+	// the totals without entries (0) in the precision of the currency
+	for i := range *res {
+		group := &(*res)[i]
+		for _, amount := range []*model.Money{&group.Amount, &group.ReversedAmount, &group.NetAmount} {
+			if amount.IsZero() {
+				*amount = model.NewMoneyFromMinorUnits(0, group.Currency)
+			}
+		}
+	}
+
+	res_summary := model.CreditSummary{	AccountID: credit.AccountID,
+										GroupBy: summaryFilter.GroupBy,
+										TimeZone: summaryFilter.Location.String(),
+										Data: *res,
+	}
+	if !summaryFilter.DateStart.IsZero() {
+		res_summary.DateStart = &summaryFilter.DateStart
+	}
+	if !summaryFilter.DateEnd.IsZero() {
+		res_summary.DateEnd = &summaryFilter.DateEnd
+	}
+
+	return &res_summary, nil
+}
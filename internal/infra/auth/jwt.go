@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+var childLogger = log.With().Str("component","go-credit").Str("package","internal.infra.auth").Logger()
+
+// the algorithms accepted, the key type must match the algorithm
+var validMethods = []string{"HS256", "RS256"}
+
+// a key of the jwks file
+type jsonWebKey struct {
+	Kty	string	`json:"kty"`
+	Kid	string	`json:"kid"`
+	Alg	string	`json:"alg"`
+	Use	string	`json:"use"`
+	N	string	`json:"n"`
+	E	string	`json:"e"`
+	K	string	`json:"k"`
+}
+
+type verifierKey struct {
+	kid	string
+	alg	string
+	key	interface{}
+}
+
+// validates the jwt of the requests with the keys of a local jwks file
+type Verifier struct {
+	authConfig	*model.AuthConfig
+	keys		[]verifierKey
+}
+
+// a scope claim is a space separated string or a list
+type scopeClaim []string
+
+func (s *scopeClaim) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*s = strings.Fields(value)
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	TenantID	string		`json:"tenant_id,omitempty"`
+	Scope		scopeClaim	`json:"scope,omitempty"`
+	Scp			scopeClaim	`json:"scp,omitempty"`
+}
+
+// About create a verifier with the keys of the jwks file
+func NewVerifier(authConfig *model.AuthConfig) (*Verifier, error) {
+	childLogger.Info().Str("func","NewVerifier").Str("jwks_file", authConfig.JwksFile).Send()
+
+	data, err := os.ReadFile(authConfig.JwksFile)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	keys, err := parseJwks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Verifier{authConfig: authConfig, keys: keys}, nil
+}
+
+// About decode a base64url value (with or without padding)
+func decodeSegment(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+// About read the RS256 (kty RSA) and HS256 (kty oct) keys of a jwks document
+func parseJwks(data []byte) ([]verifierKey, error) {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	keys := []verifierKey{}
+	for i, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		switch jwk.Kty {
+		case "RSA":
+			if jwk.Alg != "" && jwk.Alg != "RS256" {
+				continue
+			}
+			n, err := decodeSegment(jwk.N)
+			if err != nil || len(n) == 0 {
+				return nil, fmt.Errorf("jwks key %d (%s): invalid n", i, jwk.Kid)
+			}
+			e, err := decodeSegment(jwk.E)
+			if err != nil || len(e) == 0 || len(e) > 4 {
+				return nil, fmt.Errorf("jwks key %d (%s): invalid e", i, jwk.Kid)
+			}
+			public_key := &rsa.PublicKey{	N: new(big.Int).SetBytes(n),
+											E: int(new(big.Int).SetBytes(e).Int64())}
+			keys = append(keys, verifierKey{kid: jwk.Kid, alg: "RS256", key: public_key})
+		case "oct":
+			if jwk.Alg != "" && jwk.Alg != "HS256" {
+				continue
+			}
+			secret, err := decodeSegment(jwk.K)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("jwks key %d (%s): invalid k", i, jwk.Kid)
+			}
+			keys = append(keys, verifierKey{kid: jwk.Kid, alg: "HS256", key: secret})
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwks without a RS256 or HS256 signing key")
+	}
+	return keys, nil
+}
+
+// About choose the key of a token, by kid or the only key of the algorithm
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	alg := token.Method.Alg()
+	kid, _ := token.Header["kid"].(string)
+
+	var found *verifierKey
+	for i := range v.keys {
+		key := &v.keys[i]
+		if key.alg != alg {
+			continue
+		}
+		if kid != "" {
+			if key.kid == kid {
+				return key.key, nil
+			}
+			continue
+		}
+		if found != nil {
+			return nil, errors.New("token without kid and more than one key")
+		}
+		found = key
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no %s key for kid %q", alg, kid)
+	}
+	return found.key, nil
+}
+
+// About validate a token (signature, exp, nbf, iss and aud) and return its principal
+func (v *Verifier) Verify(tokenString string) (*model.Principal, error) {
+	options := []jwt.ParserOption{	jwt.WithValidMethods(validMethods),
+									jwt.WithExpirationRequired(),
+									jwt.WithLeeway(time.Duration(v.authConfig.Leeway) * time.Second)}
+	if v.authConfig.Issuer != "" {
+		options = append(options, jwt.WithIssuer(v.authConfig.Issuer))
+	}
+	if v.authConfig.Audience != "" {
+		options = append(options, jwt.WithAudience(v.authConfig.Audience))
+	}
+
+	claims := tokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, &claims, v.keyFunc, options...)
+	if err != nil {
+		childLogger.Debug().Err(err).Msg("invalid token")
+		return nil, erro.ErrUnauthorized
+	}
+
+	principal := model.Principal{	Subject: claims.Subject,
+									TenantID: claims.TenantID,
+									Scopes: append(claims.Scope, claims.Scp...),
+	}
+	return &principal, nil
+}
@@ -2,25 +2,231 @@ package circuitbreaker
 
 import (
     "time"
-	"github.com/sony/gobreaker"
+    "context"
+    "errors"
+    "sort"
+    "sync"
+    "sync/atomic"
+
+    "github.com/sony/gobreaker"
+    "github.com/rs/zerolog/log"
     "github.com/go-credit/internal/core/erro"
+    "github.com/go-credit/internal/core/model"
+
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/metric"
 )
 
+var childLogger = log.With().Str("component","go-credit").Str("package","internal.infra.circuitbreaker").Logger()
+
+// a breaker and its admin state (forced open/closed)
+type breaker struct {
+    circuitBreaker  *gobreaker.CircuitBreaker
+    config          model.CircuitBreakerConfig
+    forced          string
+    lastTransition  atomic.Int64
+}
+
+// long-lived breakers, one per downstream service
+type CircuitBreakers struct {
+    mutex           sync.RWMutex
+    breakers        map[string]*breaker
+    stateGauge      metric.Int64Gauge
+    stateCounter    metric.Int64Counter
+}
+
+// About create the breakers, one per config
+func NewCircuitBreakers(circuitBreakerConfig []model.CircuitBreakerConfig) *CircuitBreakers {
+    childLogger.Info().Str("func","NewCircuitBreakers").Send()
+
+    meter := otel.Meter("go-credit")
+    stateGauge, err := meter.Int64Gauge("circuit_breaker_state",
+                                        metric.WithDescription("circuit breaker state (0 closed, 1 half-open, 2 open)"))
+    if err != nil {
+        childLogger.Error().Err(err).Msg("error create circuit_breaker_state metric")
+    }
+    stateCounter, err := meter.Int64Counter("circuit_breaker_state_change",
+                                        metric.WithDescription("number of circuit breaker state changes"))
+    if err != nil {
+        childLogger.Error().Err(err).Msg("error create circuit_breaker_state_change metric")
+    }
+
+    c := &CircuitBreakers{
+        breakers: make(map[string]*breaker),
+        stateGauge: stateGauge,
+        stateCounter: stateCounter,
+    }
+
+    for _, config := range circuitBreakerConfig {
+        b := &breaker{ config: config }
+        b.circuitBreaker = gobreaker.NewCircuitBreaker(c.settings(b))
+        b.lastTransition.Store(time.Now().UnixNano())
+        c.breakers[config.Name] = b
+        c.recordState(config.Name, gobreaker.StateClosed)
+    }
+
+    return c
+}
+
 // About CB setup
-func CircuitBreakerConfig() *gobreaker.CircuitBreaker {
-    settings := gobreaker.Settings{
-                                        Name:    "server-circuit-breaker",
-                                        Timeout: 5 * time.Second,
-                                        Interval: 10 * time.Second,
-                                        IsSuccessful: func(err error) bool {
-                                            if (err == erro.ErrNotFound) || (err == nil) {
-                                                return true
-                                            } 
-                                            return false
-                                        },
-                                        ReadyToTrip: func(counts gobreaker.Counts) bool {
-                                            return counts.TotalFailures >= 3
-                                        },
-    }
-    return gobreaker.NewCircuitBreaker(settings)
-}
\ No newline at end of file
+func (c *CircuitBreakers) settings(b *breaker) gobreaker.Settings {
+    config := b.config
+    return gobreaker.Settings{
+                                Name:    config.Name,
+                                MaxRequests: config.MaxRequests,
+                                Timeout: time.Duration(config.Timeout) * time.Second,
+                                Interval: time.Duration(config.Interval) * time.Second,
+                                IsSuccessful: func(err error) bool {
+                                    // a business response (not found, unauthorized...) means the service is up
+                                    if (err == nil) || (err == erro.ErrNotFound) || (err == erro.ErrUnauthorized) || (err == erro.ErrHTTPForbiden) {
+                                        return true
+                                    }
+                                    // a request canceled by the caller is not a failure of the service
+                                    if errors.Is(err, context.Canceled) {
+                                        return true
+                                    }
+                                    return false
+                                },
+                                ReadyToTrip: func(counts gobreaker.Counts) bool {
+                                    return counts.TotalFailures >= config.MaxFailures
+                                },
+                                OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+                                    childLogger.Warn().Str("circuit_breaker", name).
+                                                        Str("from", from.String()).
+                                                        Str("to", to.String()).Msg("circuit breaker state changed !!!")
+
+                                    // called with the gobreaker lock held, so it must not take c.mutex
+                                    b.lastTransition.Store(time.Now().UnixNano())
+
+                                    c.recordState(name, to)
+                                },
+    }
+}
+
+// About record the breaker state metric
+func (c *CircuitBreakers) recordState(name string, state gobreaker.State) {
+    attributes := metric.WithAttributes(attribute.String("name", name))
+
+    if c.stateGauge != nil {
+        c.stateGauge.Record(context.Background(), int64(state), attributes)
+    }
+    if c.stateCounter != nil {
+        c.stateCounter.Add(context.Background(), 1, metric.WithAttributes(attribute.String("name", name),
+                                                                        attribute.String("state", state.String())))
+    }
+}
+
+// About run a request protected by the named breaker
+func (c *CircuitBreakers) Execute(name string, req func() (interface{}, error)) (interface{}, error) {
+    c.mutex.RLock()
+    b, ok := c.breakers[name]
+    var circuitBreaker *gobreaker.CircuitBreaker
+    var forced string
+    if ok {
+        circuitBreaker = b.circuitBreaker
+        forced = b.forced
+    }
+    c.mutex.RUnlock()
+
+    // a downstream without breaker or forced closed is called directly
+    if !ok || forced == "closed" {
+        return req()
+    }
+    if forced == "open" {
+        childLogger.Error().Str("circuit_breaker", name).Msg(" ****** Circuit Breaker FORCED OPEN !!! ******")
+        return nil, erro.ErrCircuitOpen
+    }
+
+    res, err := circuitBreaker.Execute(req)
+    if err == gobreaker.ErrOpenState || err == gobreaker.ErrTooManyRequests {
+        childLogger.Error().Err(err).Str("circuit_breaker", name).Msg(" ****** Circuit Breaker OPEN !!! ******")
+        return nil, erro.ErrCircuitOpen
+    }
+    return res, err
+}
+
+// About the status of all breakers
+func (c *CircuitBreakers) List() []model.CircuitBreakerStatus {
+    type snapshot struct {
+        name            string
+        circuitBreaker  *gobreaker.CircuitBreaker
+        forced          string
+        b               *breaker
+    }
+
+    // copy under the lock, the gobreaker methods are called without c.mutex
+    c.mutex.RLock()
+    snapshots := []snapshot{}
+    for name, b := range c.breakers {
+        snapshots = append(snapshots, snapshot{name: name, circuitBreaker: b.circuitBreaker, forced: b.forced, b: b})
+    }
+    c.mutex.RUnlock()
+
+    list := []model.CircuitBreakerStatus{}
+    for _, snap := range snapshots {
+        name := snap.name
+        counts := snap.circuitBreaker.Counts()
+        state := snap.circuitBreaker.State().String()
+        if snap.forced == "open" {
+            state = "forced-open"
+        } else if snap.forced == "closed" {
+            state = "forced-closed"
+        }
+
+        list = append(list, model.CircuitBreakerStatus{
+            Name: name,
+            State: state,
+            Counts: model.CircuitBreakerCounts{ Requests: counts.Requests,
+                                                TotalSuccesses: counts.TotalSuccesses,
+                                                TotalFailures: counts.TotalFailures,
+                                                ConsecutiveSuccesses: counts.ConsecutiveSuccesses,
+                                                ConsecutiveFailures: counts.ConsecutiveFailures,
+            },
+            LastTransition: time.Unix(0, snap.b.lastTransition.Load()),
+        })
+    }
+    sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+    return list
+}
+
+// About force the state of a breaker (open, close) or reset it to a new closed breaker
+func (c *CircuitBreakers) SetState(name string, action string) (*model.CircuitBreakerStatus, error) {
+    childLogger.Warn().Str("func","SetState").Str("circuit_breaker", name).Str("action", action).Send()
+
+    c.mutex.Lock()
+    b, ok := c.breakers[name]
+    if !ok {
+        c.mutex.Unlock()
+        return nil, erro.ErrNotFound
+    }
+
+    var state gobreaker.State
+    switch action {
+    case "open":
+        b.forced = "open"
+        state = gobreaker.StateOpen
+    case "close":
+        b.forced = "closed"
+        state = gobreaker.StateClosed
+    case "reset":
+        b.forced = ""
+        b.circuitBreaker = gobreaker.NewCircuitBreaker(c.settings(b))
+        state = gobreaker.StateClosed
+    default:
+        c.mutex.Unlock()
+        return nil, erro.ErrInvalidAction
+    }
+    b.lastTransition.Store(time.Now().UnixNano())
+    c.mutex.Unlock()
+
+    c.recordState(name, state)
+
+    for _, status := range c.List() {
+        if status.Name == name {
+            return &status, nil
+        }
+    }
+    return nil, erro.ErrNotFound
+}
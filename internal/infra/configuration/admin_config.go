@@ -0,0 +1,34 @@
+package configuration
+
+import(
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/go-credit/internal/core/model"
+)
+
+// About get the admin credential (secret file first, then env var)
+func GetAdminEnv() model.AdminConfig {
+	childLogger.Info().Str("func","GetAdminEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var adminConfig model.AdminConfig
+
+	file_token, err := os.ReadFile("/var/pod/secret/admin_token")
+	if err == nil {
+		adminConfig.Token = strings.TrimSpace(string(file_token))
+	} else if os.Getenv("ADMIN_TOKEN") !=  "" {
+		adminConfig.Token = os.Getenv("ADMIN_TOKEN")
+	}
+
+	if adminConfig.Token == "" {
+		childLogger.Warn().Msg("admin token not configured, the admin endpoints are disabled")
+	}
+
+	return adminConfig
+}
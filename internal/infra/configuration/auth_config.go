@@ -0,0 +1,57 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-credit/internal/core/model"
+)
+
+// About get the jwt authentication env var
+func GetAuthEnv() model.AuthConfig {
+	childLogger.Info().Str("func","GetAuthEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var authConfig model.AuthConfig
+	authConfig.Enabled = true
+	authConfig.JwksFile = "/var/pod/secret/jwks.json"
+	authConfig.Leeway = 30 // seconds
+
+	if os.Getenv("AUTH_ENABLED") == "false" {
+		authConfig.Enabled = false
+	}
+	if os.Getenv("JWT_JWKS_FILE") !=  "" {
+		authConfig.JwksFile = os.Getenv("JWT_JWKS_FILE")
+	}
+	if os.Getenv("JWT_ISSUER") !=  "" {
+		authConfig.Issuer = os.Getenv("JWT_ISSUER")
+	}
+	if os.Getenv("JWT_AUDIENCE") !=  "" {
+		authConfig.Audience = os.Getenv("JWT_AUDIENCE")
+	}
+	if os.Getenv("JWT_LEEWAY") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("JWT_LEEWAY"))
+		if err == nil && intVar >= 0 {
+			authConfig.Leeway = intVar
+		}
+	}
+
+	// dev only, without jwt the tenant of a request comes from the X-Tenant-Id header
+	if os.Getenv("AUTH_DEV_TENANT_HEADER") == "true" && !authConfig.Enabled {
+		authConfig.DevTenantHeader = true
+	}
+
+	if !authConfig.Enabled {
+		childLogger.Warn().Msg("jwt authentication disabled, the credit endpoints are refused (401) without AUTH_DEV_TENANT_HEADER")
+	}
+	if authConfig.DevTenantHeader {
+		childLogger.Warn().Msg("AUTH_DEV_TENANT_HEADER enabled, the tenant comes from the X-Tenant-Id header (dev only, never in production)")
+	}
+
+	return authConfig
+}
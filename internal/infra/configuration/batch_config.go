@@ -0,0 +1,38 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-credit/internal/core/model"
+)
+
+// About get batch ingestion env var
+func GetBatchEnv() model.BatchConfig {
+	childLogger.Info().Str("func","GetBatchEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var batchConfig model.BatchConfig
+	batchConfig.MaxItems = 1000
+	batchConfig.MaxBytes = 10485760
+
+	if os.Getenv("BATCH_MAX_ITEMS") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("BATCH_MAX_ITEMS"))
+		if intVar > 0 {
+			batchConfig.MaxItems = intVar
+		}
+	}
+	if os.Getenv("BATCH_MAX_BYTES") !=  "" {
+		intVar, _ := strconv.ParseInt(os.Getenv("BATCH_MAX_BYTES"), 10, 64)
+		if intVar > 0 {
+			batchConfig.MaxBytes = intVar
+		}
+	}
+
+	return batchConfig
+}
@@ -0,0 +1,61 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/go-credit/internal/core/model"
+)
+
+// About get the circuit breaker env var, one breaker per endpoint (CB_<NAME>_<SETTING>)
+func GetCircuitBreakerEnv(apiService map[string]model.ApiService) []model.CircuitBreakerConfig {
+	childLogger.Info().Str("func","GetCircuitBreakerEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var circuitBreakerConfig []model.CircuitBreakerConfig
+
+	names := []string{}
+	for name := range apiService {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prefix := "CB_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+
+		config := model.CircuitBreakerConfig{	Name: name,
+												MaxRequests: 1,
+												Interval: 10,
+												Timeout: 5,
+												MaxFailures: 3,
+		}
+
+		if os.Getenv(prefix + "MAX_REQUESTS") !=  "" {
+			intVar, _ := strconv.Atoi(os.Getenv(prefix + "MAX_REQUESTS"))
+			config.MaxRequests = uint32(intVar)
+		}
+		if os.Getenv(prefix + "INTERVAL") !=  "" {
+			intVar, _ := strconv.Atoi(os.Getenv(prefix + "INTERVAL"))
+			config.Interval = intVar
+		}
+		if os.Getenv(prefix + "TIMEOUT") !=  "" {
+			intVar, _ := strconv.Atoi(os.Getenv(prefix + "TIMEOUT"))
+			config.Timeout = intVar
+		}
+		if os.Getenv(prefix + "MAX_FAILURES") !=  "" {
+			intVar, _ := strconv.Atoi(os.Getenv(prefix + "MAX_FAILURES"))
+			config.MaxFailures = uint32(intVar)
+		}
+
+		circuitBreakerConfig = append(circuitBreakerConfig, config)
+	}
+
+	return circuitBreakerConfig
+}
@@ -2,66 +2,100 @@ package configuration
 
 import(
 	"os"
+	"strconv"
+	"strings"
+
 	"github.com/joho/godotenv"
 	"github.com/go-credit/internal/core/model"
 )
 
-// About get service´s endpoint env var
-func GetEndpointEnv() []model.ApiService {
+// endpoints required by go-credit, others can be added with ENDPOINTS
+var requiredEndpoint = []string{"account-get", "account-get-id", "account-balance", "fund-transfer"}
+
+// About get service´s endpoint env var (ENDPOINT_<NAME>_<SETTING>)
+func GetEndpointEnv() map[string]model.ApiService {
 	childLogger.Info().Str("func","GetEndpointEnv").Send()
 
 	err := godotenv.Load(".env")
 	if err != nil {
 		childLogger.Error().Err(err).Send()
 	}
-	
-	var apiService []model.ApiService
 
-	var apiService01 model.ApiService
-	if os.Getenv("URL_SERVICE_01") !=  "" {
-		apiService01.Url = os.Getenv("URL_SERVICE_01")
-	}
-	if os.Getenv("X_APIGW_API_ID_SERVICE_01") !=  "" {
-		apiService01.Header_x_apigw_api_id = os.Getenv("X_APIGW_API_ID_SERVICE_01")
-	}
-	if os.Getenv("METHOD_SERVICE_01") !=  "" {
-		apiService01.Method = os.Getenv("METHOD_SERVICE_01")
-	}
-	if os.Getenv("NAME_SERVICE_01") !=  "" {
-		apiService01.Name = os.Getenv("NAME_SERVICE_01")
+	names := append([]string{}, requiredEndpoint...)
+	if os.Getenv("ENDPOINTS") !=  "" {
+		for _, name := range strings.Split(os.Getenv("ENDPOINTS"), ",") {
+			name = strings.TrimSpace(name)
+			if name != "" && !contains(names, name) {
+				names = append(names, name)
+			}
+		}
 	}
-	apiService = append(apiService, apiService01)
 
-	var apiService02 model.ApiService
-	if os.Getenv("URL_SERVICE_02") !=  "" {
-		apiService02.Url = os.Getenv("URL_SERVICE_02")
-	}
-	if os.Getenv("X_APIGW_API_ID_SERVICE_02") !=  "" {
-		apiService02.Header_x_apigw_api_id = os.Getenv("X_APIGW_API_ID_SERVICE_02")
-	}
-	if os.Getenv("METHOD_SERVICE_02") !=  "" {
-		apiService02.Method = os.Getenv("METHOD_SERVICE_02")
-	}
-	if os.Getenv("NAME_SERVICE_02") !=  "" {
-		apiService02.Name = os.Getenv("NAME_SERVICE_02")
-	}
-	apiService = append(apiService, apiService02)
+	apiService := make(map[string]model.ApiService)
+	var errs []string
 
-	var apiService03 model.ApiService
-	if os.Getenv("URL_SERVICE_03") !=  "" {
-		apiService03.Url = os.Getenv("URL_SERVICE_03")
-	}
-	if os.Getenv("X_APIGW_API_ID_SERVICE_03") !=  "" {
-		apiService03.Header_x_apigw_api_id = os.Getenv("X_APIGW_API_ID_SERVICE_03")
-	}
-	if os.Getenv("METHOD_SERVICE_03") !=  "" {
-		apiService03.Method = os.Getenv("METHOD_SERVICE_03")
-	}
-	if os.Getenv("NAME_SERVICE_03") !=  "" {
-		apiService03.Name = os.Getenv("NAME_SERVICE_03")
+	for _, name := range names {
+		prefix := "ENDPOINT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+
+		var endpoint model.ApiService
+		endpoint.Name = name
+		endpoint.Timeout = 29
+		endpoint.Retry = 0
+
+		if os.Getenv(prefix + "NAME") !=  "" {
+			endpoint.Name = os.Getenv(prefix + "NAME")
+		}
+		if os.Getenv(prefix + "URL") !=  "" {
+			endpoint.Url = os.Getenv(prefix + "URL")
+		}
+		if os.Getenv(prefix + "METHOD") !=  "" {
+			endpoint.Method = os.Getenv(prefix + "METHOD")
+		}
+		if os.Getenv(prefix + "X_APIGW_API_ID") !=  "" {
+			endpoint.Header_x_apigw_api_id = os.Getenv(prefix + "X_APIGW_API_ID")
+		}
+		if os.Getenv(prefix + "TIMEOUT") !=  "" {
+			intVar, err := strconv.Atoi(os.Getenv(prefix + "TIMEOUT"))
+			if err != nil || intVar <= 0 {
+				errs = append(errs, prefix + "TIMEOUT must be a positive number of seconds")
+			}
+			endpoint.Timeout = intVar
+		}
+		if os.Getenv(prefix + "RETRY") !=  "" {
+			intVar, err := strconv.Atoi(os.Getenv(prefix + "RETRY"))
+			if err != nil || intVar < 0 {
+				errs = append(errs, prefix + "RETRY must be zero or a positive number")
+			}
+			endpoint.Retry = intVar
+		}
+
+		// fail fast, every endpoint needs url, method and api gw id
+		if endpoint.Url == "" {
+			errs = append(errs, prefix + "URL is missing")
+		}
+		if endpoint.Method == "" {
+			errs = append(errs, prefix + "METHOD is missing")
+		}
+		if endpoint.Header_x_apigw_api_id == "" {
+			errs = append(errs, prefix + "X_APIGW_API_ID is missing")
+		}
+
+		apiService[name] = endpoint
 	}
 
-	apiService = append(apiService, apiService03)
+	if len(errs) > 0 {
+		childLogger.Error().Strs("errors", errs).Msg("invalid endpoint configuration, aborting")
+		os.Exit(3)
+	}
 
 	return apiService
-}
\ No newline at end of file
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
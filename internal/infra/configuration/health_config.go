@@ -0,0 +1,42 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/go-credit/internal/core/model"
+)
+
+// About get the readiness check env var
+func GetHealthEnv() model.HealthConfig {
+	childLogger.Info().Str("func","GetHealthEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var healthConfig model.HealthConfig
+	healthConfig.Timeout = 1000 // milliseconds
+	healthConfig.Critical = []string{"database"}
+
+	if os.Getenv("HEALTH_TIMEOUT_MS") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("HEALTH_TIMEOUT_MS"))
+		if intVar > 0 {
+			healthConfig.Timeout = intVar
+		}
+	}
+	if os.Getenv("HEALTH_CRITICAL") !=  "" {
+		healthConfig.Critical = []string{}
+		for _, name := range strings.Split(os.Getenv("HEALTH_CRITICAL"), ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				healthConfig.Critical = append(healthConfig.Critical, name)
+			}
+		}
+	}
+
+	return healthConfig
+}
@@ -0,0 +1,38 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-credit/internal/core/model"
+)
+
+// About get hold (ttl and expiry worker) env var
+func GetHoldEnv() model.HoldConfig {
+	childLogger.Info().Str("func","GetHoldEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var holdConfig model.HoldConfig
+	holdConfig.TTL = 900 // seconds
+	holdConfig.ExpiryInterval = 30
+
+	if os.Getenv("HOLD_TTL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("HOLD_TTL"))
+		if intVar > 0 {
+			holdConfig.TTL = intVar
+		}
+	}
+	if os.Getenv("HOLD_EXPIRY_INTERVAL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("HOLD_EXPIRY_INTERVAL"))
+		if intVar > 0 {
+			holdConfig.ExpiryInterval = intVar
+		}
+	}
+
+	return holdConfig
+}
@@ -0,0 +1,33 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-credit/internal/core/model"
+)
+
+// About get idempotency env var
+func GetIdempotencyEnv() model.IdempotencyConfig {
+	childLogger.Info().Str("func","GetIdempotencyEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var idempotencyConfig model.IdempotencyConfig
+	idempotencyConfig.ExpirationWindow = 86400 // 24h in seconds
+
+	if os.Getenv("IDEMPOTENCY_KEY_EXPIRATION") !=  "" {
+		intVar, err := strconv.Atoi(os.Getenv("IDEMPOTENCY_KEY_EXPIRATION"))
+		if err != nil {
+			childLogger.Error().Err(err).Msg("invalid IDEMPOTENCY_KEY_EXPIRATION, using default")
+		} else {
+			idempotencyConfig.ExpirationWindow = intVar
+		}
+	}
+
+	return idempotencyConfig
+}
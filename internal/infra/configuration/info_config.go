@@ -0,0 +1,53 @@
+package configuration
+
+import(
+	"os"
+	"runtime/debug"
+
+	"github.com/go-credit/internal/core/model"
+)
+
+// About get the build information embedded by the go toolchain
+func GetBuildInfo() model.BuildInfo {
+	childLogger.Info().Str("func","GetBuildInfo").Send()
+
+	var buildInfo model.BuildInfo
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return buildInfo
+	}
+	buildInfo.GoVersion = info.GoVersion
+	buildInfo.Module = info.Main.Path
+	buildInfo.Version = info.Main.Version
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			buildInfo.Revision = setting.Value
+		case "vcs.time":
+			buildInfo.RevisionTime = setting.Value
+		case "vcs.modified":
+			buildInfo.Modified = setting.Value == "true"
+		}
+	}
+
+	return buildInfo
+}
+
+// About get where the configuration is read from (.env file, env var, secret files)
+func GetConfigSource(authConfig *model.AuthConfig) model.ConfigSource {
+	childLogger.Info().Str("func","GetConfigSource").Send()
+
+	var configSource model.ConfigSource
+	configSource.Environment = true
+	configSource.SecretPath = "/var/pod/secret"
+
+	if _, err := os.Stat(".env"); err == nil {
+		configSource.EnvFile = ".env"
+	}
+	if authConfig != nil && authConfig.Enabled {
+		configSource.JwksFile = authConfig.JwksFile
+	}
+
+	return configSource
+}
@@ -0,0 +1,43 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-credit/internal/core/model"
+)
+
+// About get list (pagination and date range) env var
+func GetListEnv() model.ListConfig {
+	childLogger.Info().Str("func","GetListEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var listConfig model.ListConfig
+	listConfig.DefaultLimit = 50
+	listConfig.MaxLimit = 500
+	listConfig.MaxDateRange = 90 // days
+
+	if os.Getenv("LIST_DEFAULT_LIMIT") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("LIST_DEFAULT_LIMIT"))
+		if intVar > 0 {
+			listConfig.DefaultLimit = intVar
+		}
+	}
+	if os.Getenv("LIST_MAX_LIMIT") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("LIST_MAX_LIMIT"))
+		if intVar > 0 {
+			listConfig.MaxLimit = intVar
+		}
+	}
+	if os.Getenv("LIST_MAX_DATE_RANGE") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("LIST_MAX_DATE_RANGE"))
+		listConfig.MaxDateRange = intVar
+	}
+
+	return listConfig
+}
@@ -0,0 +1,28 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-credit/internal/core/model"
+)
+
+// About get migration env var
+func GetMigrationEnv() model.MigrationConfig {
+	childLogger.Info().Str("func","GetMigrationEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var migrationConfig model.MigrationConfig
+
+	if os.Getenv("DB_SCHEMA_CHECK") !=  "" {
+		boolVar, _ := strconv.ParseBool(os.Getenv("DB_SCHEMA_CHECK"))
+		migrationConfig.SchemaCheck = boolVar
+	}
+
+	return migrationConfig
+}
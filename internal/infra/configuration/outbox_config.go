@@ -0,0 +1,62 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-credit/internal/core/model"
+)
+
+// About get outbox relay env var
+func GetOutboxEnv() model.OutboxConfig {
+	childLogger.Info().Str("func","GetOutboxEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var outboxConfig model.OutboxConfig
+	outboxConfig.PollInterval = 2
+	outboxConfig.BatchSize = 10
+	outboxConfig.MaxAttempts = 10
+	outboxConfig.BackoffBase = 1
+	outboxConfig.BackoffMax = 300
+	outboxConfig.ClaimTimeout = 60
+
+	if os.Getenv("OUTBOX_POLL_INTERVAL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("OUTBOX_POLL_INTERVAL"))
+		if intVar > 0 {
+			outboxConfig.PollInterval = intVar
+		}
+	}
+	if os.Getenv("OUTBOX_BATCH_SIZE") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("OUTBOX_BATCH_SIZE"))
+		if intVar > 0 {
+			outboxConfig.BatchSize = intVar
+		}
+	}
+	if os.Getenv("OUTBOX_MAX_ATTEMPTS") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS"))
+		if intVar > 0 {
+			outboxConfig.MaxAttempts = intVar
+		}
+	}
+	if os.Getenv("OUTBOX_BACKOFF_BASE") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("OUTBOX_BACKOFF_BASE"))
+		outboxConfig.BackoffBase = intVar
+	}
+	if os.Getenv("OUTBOX_BACKOFF_MAX") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("OUTBOX_BACKOFF_MAX"))
+		outboxConfig.BackoffMax = intVar
+	}
+	if os.Getenv("OUTBOX_CLAIM_TIMEOUT") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("OUTBOX_CLAIM_TIMEOUT"))
+		if intVar > 0 {
+			outboxConfig.ClaimTimeout = intVar
+		}
+	}
+
+	return outboxConfig
+}
@@ -0,0 +1,34 @@
+package configuration
+
+import(
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/go-credit/internal/core/model"
+)
+
+// About get the trace propagators env var (tracecontext, baggage, xray, b3, b3multi)
+func GetPropagatorEnv() model.PropagatorConfig {
+	childLogger.Info().Str("func","GetPropagatorEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var propagatorConfig model.PropagatorConfig
+	propagatorConfig.Propagators = []string{"tracecontext", "baggage", "xray"}
+
+	if os.Getenv("OTEL_PROPAGATORS") !=  "" {
+		propagatorConfig.Propagators = []string{}
+		for _, name := range strings.Split(os.Getenv("OTEL_PROPAGATORS"), ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != "" {
+				propagatorConfig.Propagators = append(propagatorConfig.Propagators, name)
+			}
+		}
+	}
+
+	return propagatorConfig
+}
@@ -0,0 +1,346 @@
+package migration
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/core/erro"
+	go_core_pg "github.com/eliezerraj/go-core/database/pg"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+var childLogger = log.With().Str("component","go-credit").Str("package","internal.infra.migration").Logger()
+
+// the migrations, <version>_<name>.up.sql and <version>_<name>.down.sql
+//go:embed sql/*.sql
+var migrationFS embed.FS
+
+// serializes the migrations of all the pods
+const migrationLockID = 7414401
+
+type Migration struct {
+	Version	int
+	Name	string
+	Up		string
+	Down	string
+}
+
+type Migrator struct {
+	DatabasePGServer	*go_core_pg.DatabasePGServer
+	migrations			[]Migration
+}
+
+// About create a migrator with the embedded migrations
+func NewMigrator(databasePGServer *go_core_pg.DatabasePGServer) (*Migrator, error) {
+	childLogger.Info().Str("func","NewMigrator").Send()
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		DatabasePGServer: databasePGServer,
+		migrations: migrations,
+	}, nil
+}
+
+// About read the embedded migrations ordered by version
+func LoadMigrations() ([]Migration, error) {
+	files, err := fs.Glob(migrationFS, "sql/*.sql")
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, file := range files {
+		base := path.Base(file)
+		direction := ""
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s must end with .up.sql or .down.sql", base)
+		}
+
+		prefix, name, found := strings.Cut(strings.TrimSuffix(base, "." + direction + ".sql"), "_")
+		version, err := strconv.Atoi(prefix)
+		if !found || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s must start with <version>_", base)
+		}
+
+		content, err := migrationFS.ReadFile(file)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration %d has two names (%s, %s)", version, migration.Name, name)
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d must have an up and a down file", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// About the version of the last embedded migration
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations) - 1].Version
+}
+
+// About create the migration table
+func (m *Migrator) createTable(ctx context.Context) error {
+	conn, err := m.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	defer m.DatabasePGServer.Release(conn)
+
+	query := `CREATE TABLE IF NOT EXISTS public.credit_schema_migration (
+					version		int4 NOT NULL,
+					name		varchar(200) NOT NULL,
+					applied_at	timestamptz NOT NULL,
+					CONSTRAINT credit_schema_migration_pkey PRIMARY KEY (version)
+				)`
+
+	_, err = conn.Exec(ctx, query)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	return nil
+}
+
+// About the applied migrations (version => applied_at)
+func (m *Migrator) applied(ctx context.Context, tx pgx.Tx) (map[int]time.Time, error) {
+	query := `SELECT version, applied_at FROM public.credit_schema_migration`
+
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var applied_at time.Time
+		if err := rows.Scan(&version, &applied_at); err != nil {
+			return nil, errors.New(err.Error())
+		}
+		applied[version] = applied_at
+	}
+	return applied, rows.Err()
+}
+
+// About run a function in a transaction holding the migration lock
+func (m *Migrator) withLock(ctx context.Context, fn func(tx pgx.Tx) error) (err error) {
+	err = m.createTable(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, conn, err := m.DatabasePGServer.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+		m.DatabasePGServer.ReleaseTx(conn)
+	}()
+
+	_, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	err = fn(tx)
+	return err
+}
+
+// About apply the pending migrations, each one in its own transaction
+func (m *Migrator) Up(ctx context.Context) ([]model.MigrationStatus, error) {
+	childLogger.Info().Str("func","Up").Send()
+
+	res_list := []model.MigrationStatus{}
+	for _, migration := range m.migrations {
+		done := false
+
+		err := m.withLock(ctx, func(tx pgx.Tx) error {
+			applied, err := m.applied(ctx, tx)
+			if err != nil {
+				return err
+			}
+			if _, ok := applied[migration.Version]; ok {
+				return nil
+			}
+
+			childLogger.Info().Int("version", migration.Version).Str("name", migration.Name).Msg("apply migration")
+
+			_, err = tx.Exec(ctx, migration.Up)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			_, err = tx.Exec(ctx, `INSERT INTO public.credit_schema_migration (version, name, applied_at) VALUES($1, $2, $3)`,
+								migration.Version, migration.Name, time.Now())
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			done = true
+			return nil
+		})
+		if err != nil {
+			return res_list, err
+		}
+
+		if done {
+			applied_at := time.Now()
+			res_list = append(res_list, model.MigrationStatus{	Version: migration.Version,
+																Name: migration.Name,
+																Applied: true,
+																AppliedAt: &applied_at})
+		}
+	}
+
+	return res_list, nil
+}
+
+// About roll back the last applied migration
+func (m *Migrator) Down(ctx context.Context) (*model.MigrationStatus, error) {
+	childLogger.Info().Str("func","Down").Send()
+
+	var res *model.MigrationStatus
+	err := m.withLock(ctx, func(tx pgx.Tx) error {
+		applied, err := m.applied(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			childLogger.Info().Int("version", migration.Version).Str("name", migration.Name).Msg("roll back migration")
+
+			_, err = tx.Exec(ctx, migration.Down)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			_, err = tx.Exec(ctx, `DELETE FROM public.credit_schema_migration WHERE version = $1`, migration.Version)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			res = &model.MigrationStatus{Version: migration.Version, Name: migration.Name, Applied: false}
+			return nil
+		}
+		return erro.ErrNoMigration
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// About the status of every embedded migration
+func (m *Migrator) Status(ctx context.Context) ([]model.MigrationStatus, error) {
+	childLogger.Info().Str("func","Status").Send()
+
+	// read only, the migration table may not exist yet
+	tx, conn, err := m.DatabasePGServer.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		tx.Rollback(ctx)
+		m.DatabasePGServer.ReleaseTx(conn)
+	}()
+
+	var exists bool
+	err = tx.QueryRow(ctx, `SELECT to_regclass('public.credit_schema_migration') IS NOT NULL`).Scan(&exists)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	applied := make(map[int]time.Time)
+	if exists {
+		applied, err = m.applied(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res_list := []model.MigrationStatus{}
+	for _, migration := range m.migrations {
+		status := model.MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if applied_at, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &applied_at
+		}
+		res_list = append(res_list, status)
+	}
+
+	return res_list, nil
+}
+
+// About refuse a schema without all the embedded migrations
+func (m *Migrator) CheckSchema(ctx context.Context) error {
+	childLogger.Info().Str("func","CheckSchema").Send()
+
+	res_list, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending := []string{}
+	for _, status := range res_list {
+		if !status.Applied {
+			pending = append(pending, fmt.Sprintf("%04d_%s", status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		childLogger.Error().Strs("pending", pending).Int("latest", m.Latest()).Msg("database schema is behind, run go-credit migrate up")
+		return erro.ErrSchemaBehind
+	}
+
+	return nil
+}
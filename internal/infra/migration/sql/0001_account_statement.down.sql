@@ -0,0 +1,2 @@
+-- account_statement is shared with go-account, it is never dropped by go-credit
+SELECT 1;
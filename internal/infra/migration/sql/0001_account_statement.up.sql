@@ -0,0 +1,14 @@
+-- account_statement (created by go-account-migration-worker, kept here to check the columns used by go-credit)
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS public.account_statement (
+	id				serial4 NOT NULL,
+	fk_account_id	int4 NOT NULL,
+	type_charge		varchar(200) NOT NULL,
+	charged_at		timestamptz NOT NULL,
+	currency		varchar(10) NOT NULL,
+	amount			numeric NOT NULL,
+	tenant_id		varchar(200) NULL,
+	transaction_id	varchar(200) NULL,
+	CONSTRAINT account_statement_pkey PRIMARY KEY (id)
+);
@@ -0,0 +1,13 @@
+-- idempotency keys used by POST /add
+CREATE TABLE IF NOT EXISTS public.credit_idempotency (
+	idempotency_key	varchar(200) NOT NULL,
+	tenant_id		varchar(200) NULL,
+	request_hash	varchar(64) NOT NULL,
+	status_code		int4 NOT NULL DEFAULT 0,
+	response		jsonb NULL,
+	created_at		timestamptz NOT NULL,
+	expires_at		timestamptz NOT NULL,
+	CONSTRAINT credit_idempotency_pkey PRIMARY KEY (idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS credit_idempotency_expires_at_idx ON public.credit_idempotency (expires_at);
@@ -0,0 +1,16 @@
+-- outbox events written in the same transaction as account_statement
+CREATE TABLE IF NOT EXISTS public.credit_outbox (
+	id				serial4 NOT NULL,
+	aggregate_id	int4 NOT NULL,
+	event_type		varchar(100) NOT NULL,
+	payload			jsonb NOT NULL,
+	status			varchar(20) NOT NULL DEFAULT 'PENDING',
+	attempts		int4 NOT NULL DEFAULT 0,
+	next_attempt_at	timestamptz NOT NULL,
+	last_error		text NULL,
+	created_at		timestamptz NOT NULL,
+	updated_at		timestamptz NULL,
+	CONSTRAINT credit_outbox_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS credit_outbox_pending_idx ON public.credit_outbox (next_attempt_at) WHERE status = 'PENDING';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS public.account_statement_transaction_id_idx;
+DROP INDEX IF EXISTS public.account_statement_reversal_of_idx;
+ALTER TABLE public.account_statement DROP COLUMN IF EXISTS reversal_of;
@@ -0,0 +1,5 @@
+-- link between a CREDIT-REVERSAL and the original credit
+ALTER TABLE public.account_statement ADD COLUMN IF NOT EXISTS reversal_of varchar(200) NULL;
+
+CREATE INDEX IF NOT EXISTS account_statement_reversal_of_idx ON public.account_statement (reversal_of) WHERE reversal_of IS NOT NULL;
+CREATE INDEX IF NOT EXISTS account_statement_transaction_id_idx ON public.account_statement (transaction_id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS public.account_statement_account_charged_at_idx;
@@ -0,0 +1,2 @@
+-- keyset pagination of GET /list/{id} (fk_account_id, charged_at desc, id desc)
+CREATE INDEX IF NOT EXISTS account_statement_account_charged_at_idx ON public.account_statement (fk_account_id, charged_at DESC, id DESC);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS public.account_statement_metadata_idx;
+ALTER TABLE public.account_statement DROP COLUMN IF EXISTS metadata;
+ALTER TABLE public.account_statement DROP COLUMN IF EXISTS obs;
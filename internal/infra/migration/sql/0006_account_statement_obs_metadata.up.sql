@@ -0,0 +1,6 @@
+-- obs and free-form metadata (merchant, campaign, external reference...)
+ALTER TABLE public.account_statement ADD COLUMN IF NOT EXISTS obs varchar(200) NULL;
+ALTER TABLE public.account_statement ADD COLUMN IF NOT EXISTS metadata jsonb NULL;
+
+-- metadata filter of the list endpoints (metadata @> '{"key": "value"}')
+CREATE INDEX IF NOT EXISTS account_statement_metadata_idx ON public.account_statement USING gin (metadata jsonb_path_ops);
@@ -0,0 +1,19 @@
+-- two-phase credits, a hold does not touch the balance until captured
+CREATE TABLE IF NOT EXISTS public.credit_hold (
+	id				serial4 NOT NULL,
+	fk_account_id	int4 NOT NULL,
+	currency		varchar(10) NOT NULL,
+	amount			numeric NOT NULL,
+	tenant_id		varchar(200) NULL,
+	transaction_id	varchar(200) NOT NULL,
+	status			varchar(20) NOT NULL DEFAULT 'PENDING',
+	obs				varchar(200) NULL,
+	metadata		jsonb NULL,
+	fk_credit_id	int4 NULL,
+	created_at		timestamptz NOT NULL,
+	expires_at		timestamptz NOT NULL,
+	updated_at		timestamptz NULL,
+	CONSTRAINT credit_hold_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS credit_hold_expires_at_idx ON public.credit_hold (expires_at) WHERE status = 'PENDING';
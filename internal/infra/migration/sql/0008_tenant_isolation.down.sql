@@ -0,0 +1,8 @@
+-- a key used by more than one tenant keeps only the last one
+DELETE FROM public.credit_idempotency a
+	USING public.credit_idempotency b
+	WHERE a.idempotency_key = b.idempotency_key
+	and a.created_at < b.created_at;
+ALTER TABLE public.credit_idempotency DROP CONSTRAINT IF EXISTS credit_idempotency_pkey;
+ALTER TABLE public.credit_idempotency ADD CONSTRAINT credit_idempotency_pkey PRIMARY KEY (idempotency_key);
+ALTER TABLE public.credit_idempotency ALTER COLUMN tenant_id DROP NOT NULL;
@@ -0,0 +1,5 @@
+-- the idempotency keys are unique per tenant
+UPDATE public.credit_idempotency SET tenant_id = '' WHERE tenant_id IS NULL;
+ALTER TABLE public.credit_idempotency ALTER COLUMN tenant_id SET NOT NULL;
+ALTER TABLE public.credit_idempotency DROP CONSTRAINT IF EXISTS credit_idempotency_pkey;
+ALTER TABLE public.credit_idempotency ADD CONSTRAINT credit_idempotency_pkey PRIMARY KEY (tenant_id, idempotency_key);
@@ -0,0 +1 @@
+ALTER TABLE public.credit_outbox DROP COLUMN IF EXISTS claim_token;
@@ -0,0 +1,2 @@
+-- the relay that claimed a outbox event, the status is only written with the current claim
+ALTER TABLE public.credit_outbox ADD COLUMN IF NOT EXISTS claim_token varchar(36) NULL;
@@ -2,7 +2,6 @@ package server
 
 import (
 	"time"
-	"encoding/json"
 	"net/http"
 	"strconv"
 	"os"
@@ -12,6 +11,7 @@ import (
 
 	"github.com/go-credit/internal/adapter/api"
 	"github.com/go-credit/internal/core/model"
+	"github.com/go-credit/internal/infra/telemetry"
 	go_core_observ "github.com/eliezerraj/go-core/observability"  
 
 	"github.com/gorilla/mux"
@@ -20,7 +20,6 @@ import (
 	"github.com/eliezerraj/go-core/middleware"
 
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/contrib/propagators/aws/xray"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
@@ -44,6 +43,8 @@ func NewHttpAppServer(httpServer *model.Server) HttpServer {
 // About start http server
 func (h HttpServer) StartHttpAppServer(	ctx context.Context, 
 										httpRouters *api.HttpRouters,
+										tokenVerifier api.TokenVerifier,
+										metricsHandler http.Handler,
 										appServer *model.AppServer) {
 	childLogger.Info().Str("func","StartHttpAppServer").Send()
 			
@@ -58,7 +59,8 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 											appServer.ConfigOTEL, 
 											&infoTrace)
 
-	otel.SetTextMapPropagator(xray.Propagator{})
+	// the outbound calls (otelhttp transport) inject the same propagators
+	otel.SetTextMapPropagator(telemetry.NewPropagator(appServer.PropagatorConfig))
 	otel.SetTracerProvider(tp)
 
 	// handle defer
@@ -73,12 +75,9 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 	// router
 	myRouter := mux.NewRouter().StrictSlash(true)
 	myRouter.Use(core_middleware.MiddleWareHandlerHeader)
+	myRouter.Use(api.MetricsMiddleware())
 
-	myRouter.HandleFunc("/", func(rw http.ResponseWriter, req *http.Request) {
-		childLogger.Info().Str("HandleFunc","/").Send()
-
-		json.NewEncoder(rw).Encode(appServer)
-	})
+	myRouter.HandleFunc("/", httpRouters.AppServer)
 
 	health := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
     health.HandleFunc("/health", httpRouters.Health)
@@ -86,27 +85,61 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 	live := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
     live.HandleFunc("/live", httpRouters.Live)
 
+	metrics := myRouter.Methods(http.MethodGet).Subrouter()
+	metrics.Handle("/metrics", metricsHandler)
+
 	header := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
     header.HandleFunc("/header", httpRouters.Header)
 
-	myRouter.HandleFunc("/info", func(rw http.ResponseWriter, req *http.Request) {
-		childLogger.Info().Str("HandleFunc","/info").Send()
-
-		rw.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(rw).Encode(appServer)
-	})
+	myRouter.HandleFunc("/info", core_middleware.MiddleWareErrorHandler(httpRouters.Info))
 	
 	addCredit := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
 	addCredit.HandleFunc("/add", core_middleware.MiddleWareErrorHandler(httpRouters.AddCredit))		
+	addCredit.HandleFunc("/add/batch", core_middleware.MiddleWareErrorHandler(httpRouters.AddCreditBatch))
 	addCredit.Use(otelmux.Middleware("go-credit"))
+	addCredit.Use(api.AuthMiddleware(tokenVerifier, api.ScopeCreditWrite))
+
+	addReversal := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addReversal.HandleFunc("/reversal/{transaction_id}", core_middleware.MiddleWareErrorHandler(httpRouters.AddReversal))		
+	addReversal.Use(otelmux.Middleware("go-credit"))
+	addReversal.Use(api.AuthMiddleware(tokenVerifier, api.ScopeCreditWrite))
+
+	hold := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	hold.HandleFunc("/hold", core_middleware.MiddleWareErrorHandler(httpRouters.AddHold))
+	hold.HandleFunc("/hold/{id:[0-9]+}/{action:capture|void}", core_middleware.MiddleWareErrorHandler(httpRouters.UpdateHold))
+	hold.Use(otelmux.Middleware("go-credit"))
+	hold.Use(api.AuthMiddleware(tokenVerifier, api.ScopeCreditWrite))
+
+	exportCredit := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	exportCredit.HandleFunc("/list/{id}/export", core_middleware.MiddleWareErrorHandler(httpRouters.ExportCredit))
+	exportCredit.Use(otelmux.Middleware("go-credit"))
+	exportCredit.Use(api.AuthMiddleware(tokenVerifier, api.ScopeCreditRead))
 
 	listCredit := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
 	listCredit.HandleFunc("/list/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.ListCredit))		
 	listCredit.Use(otelmux.Middleware("go-credit"))
+	listCredit.Use(api.AuthMiddleware(tokenVerifier, api.ScopeCreditRead))
+
+	summaryCredit := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	summaryCredit.HandleFunc("/summary/{account_id}", core_middleware.MiddleWareErrorHandler(httpRouters.SummaryCredit))
+	summaryCredit.Use(otelmux.Middleware("go-credit"))
+	summaryCredit.Use(api.AuthMiddleware(tokenVerifier, api.ScopeCreditRead))
+
+	getCredit := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getCredit.HandleFunc("/credit/{id:[0-9]+}", core_middleware.MiddleWareErrorHandler(httpRouters.GetCredit))
+	getCredit.HandleFunc("/credit/transaction/{transaction_id}", core_middleware.MiddleWareErrorHandler(httpRouters.GetCredit))
+	getCredit.Use(otelmux.Middleware("go-credit"))
+	getCredit.Use(api.AuthMiddleware(tokenVerifier, api.ScopeCreditRead))
 
 	listCreditDate := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
 	listCreditDate.HandleFunc("/listPerDate", core_middleware.MiddleWareErrorHandler(httpRouters.ListCreditPerDate))		
 	listCreditDate.Use(otelmux.Middleware("go-credit"))
+	listCreditDate.Use(api.AuthMiddleware(tokenVerifier, api.ScopeCreditRead))
+
+	adminCircuitBreaker := myRouter.PathPrefix("/admin").Subrouter()
+	adminCircuitBreaker.HandleFunc("/circuit-breakers", core_middleware.MiddleWareErrorHandler(httpRouters.ListCircuitBreaker)).Methods(http.MethodGet)
+	adminCircuitBreaker.HandleFunc("/circuit-breakers/{name}/{action:open|close|reset}", core_middleware.MiddleWareErrorHandler(httpRouters.SetCircuitBreaker)).Methods(http.MethodPost)
+	adminCircuitBreaker.Use(api.AdminMiddleware(appServer.AdminConfig))
 
 	// setup http server
 	srv := http.Server{
@@ -0,0 +1,86 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-credit/internal/core/model"
+	go_core_observ "github.com/eliezerraj/go-core/observability"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	otel_prometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdk_metric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+var childLogger = log.With().Str("component","go-credit").Str("package","internal.infra.telemetry").Logger()
+
+// the meter provider of the service, the metrics are read by the prometheus scrape of /metrics
+// and pushed over OTLP to the collector (when there is one)
+type MeterProvider struct {
+	meterProvider	*sdk_metric.MeterProvider
+	registry		*prometheus.Registry
+}
+
+// About create the meter provider with a prometheus reader, plus the go runtime and process metrics.
+// With a otel endpoint the same metrics are exported by a periodic OTLP reader (OTEL_METRIC_EXPORT_INTERVAL, default 60s)
+func NewMeterProvider(ctx context.Context, infoPod *model.InfoPod, configOTEL *go_core_observ.ConfigOTEL) (*MeterProvider, error) {
+	childLogger.Info().Str("func","NewMeterProvider").Send()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(	collectors.NewGoCollector(),
+							collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	exporter, err := otel_prometheus.New(otel_prometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	res := resource.NewSchemaless(	attribute.String("service.name", "go-credit"),
+									attribute.String("service.version", infoPod.ApiVersion),
+									attribute.String("service.instance.id", infoPod.PodName),
+									attribute.String("deployment.environment", infoPod.Env))
+
+	options := []sdk_metric.Option{	sdk_metric.WithResource(res),
+									sdk_metric.WithReader(exporter)}
+
+	if configOTEL.OtelExportEndpoint != "" {
+		otlpExporter, err := otlpmetricgrpc.New(ctx,
+												otlpmetricgrpc.WithEndpoint(configOTEL.OtelExportEndpoint),
+												otlpmetricgrpc.WithInsecure())
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+		options = append(options, sdk_metric.WithReader(sdk_metric.NewPeriodicReader(otlpExporter)))
+	} else {
+		childLogger.Warn().Msg("otel endpoint without value, the metrics are only exposed in /metrics")
+	}
+
+	meterProvider := sdk_metric.NewMeterProvider(options...)
+
+	return &MeterProvider{	meterProvider: meterProvider,
+							registry: registry,
+	}, nil
+}
+
+// About the provider to register as the otel global meter provider
+func (m *MeterProvider) Provider() *sdk_metric.MeterProvider {
+	return m.meterProvider
+}
+
+// About the handler of the prometheus scrape (text exposition format)
+func (m *MeterProvider) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// About flush (the last OTLP export) and stop the meter provider
+func (m *MeterProvider) Shutdown(ctx context.Context) error {
+	return m.meterProvider.Shutdown(ctx)
+}
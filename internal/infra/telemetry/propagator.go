@@ -0,0 +1,36 @@
+package telemetry
+
+import (
+	"github.com/go-credit/internal/core/model"
+
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// About create the composite propagator of the configured names, an unknown name is skipped.
+// On extract the propagators are applied in order, the last one with a valid header wins
+func NewPropagator(propagatorConfig *model.PropagatorConfig) propagation.TextMapPropagator {
+	childLogger.Info().Str("func","NewPropagator").Strs("propagators", propagatorConfig.Propagators).Send()
+
+	propagators := []propagation.TextMapPropagator{}
+	for _, name := range propagatorConfig.Propagators {
+		switch name {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "xray":
+			propagators = append(propagators, xray.Propagator{})
+		case "b3":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "none":
+		default:
+			childLogger.Warn().Str("propagator", name).Msg("unknown propagator skipped")
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...)
+}
@@ -0,0 +1,79 @@
+package telemetry
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-credit/internal/core/model"
+)
+
+// the upper bounds of the amount buckets, the amount itself is not a span attribute
+var amountBuckets = []struct {
+	limit	float64
+	name	string
+}{
+	{10, "0-10"},
+	{100, "10-100"},
+	{1000, "100-1k"},
+	{10000, "1k-10k"},
+	{100000, "10k-100k"},
+}
+
+// About record a failure on the span (error event and status), a nil error is ignored
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// About the order of magnitude of an amount (absolute value)
+func AmountBucket(amount model.Money) string {
+	value := amount.Abs().Float64()
+	for _, bucket := range amountBuckets {
+		if value < bucket.limit {
+			return bucket.name
+		}
+	}
+	return "100k+"
+}
+
+// About set the account, tenant, transaction and amount bucket of a credit on the span, the fields without value are skipped
+func SetCreditAttributes(span trace.Span, credit *model.AccountStatement) {
+	span.SetAttributes(spanAttributes(credit.AccountID, credit.TenantID, credit.TransactionID, credit.Currency, credit.Amount)...)
+	if credit.Type != "" {
+		span.SetAttributes(attribute.String("type", credit.Type))
+	}
+}
+
+// About set the account, tenant, transaction and amount bucket of a hold on the span, the fields without value are skipped
+func SetHoldAttributes(span trace.Span, hold *model.Hold) {
+	span.SetAttributes(spanAttributes(hold.AccountID, hold.TenantID, hold.TransactionID, hold.Currency, hold.Amount)...)
+}
+
+func spanAttributes(accountID string, tenantID string, transactionID *string, currency string, amount model.Money) []attribute.KeyValue {
+	attributes := []attribute.KeyValue{}
+	if accountID != "" {
+		attributes = append(attributes, attribute.String("account_id", accountID))
+	}
+	if tenantID != "" {
+		attributes = append(attributes, attribute.String("tenant_id", tenantID))
+	}
+	if transactionID != nil && *transactionID != "" {
+		attributes = append(attributes, attribute.String("transaction_id", *transactionID))
+	}
+	if currency != "" {
+		attributes = append(attributes, attribute.String("currency", currency))
+	}
+	if !amount.IsZero() {
+		attributes = append(attributes, attribute.String("amount_bucket", AmountBucket(amount)))
+	}
+	return attributes
+}
+
+// About set the name of the downstream endpoint of a call on the span
+func SetDownstream(span trace.Span, name string) {
+	span.SetAttributes(attribute.String("downstream", name))
+}